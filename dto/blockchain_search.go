@@ -20,4 +20,13 @@ type BlockchainSearchDTO struct {
 type BlockchainSearchResponse struct {
 	ID              uuid.UUID `json:"id"`
 	ContractAddress string    `json:"contract_address"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// BlockchainSearchSnapshot merepresentasikan satu snapshot hasil pencarian sebuah kontrak
+type BlockchainSearchSnapshot struct {
+	ID              uuid.UUID                `json:"id"`
+	ContractAddress string                   `json:"contract_address"`
+	CreatedAt       time.Time                `json:"created_at"`
+	Response        *ContractAddressResponse `json:"response"`
 }
@@ -1,19 +1,32 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type ContractAddressResponse struct {
 	ID              string           `json:"id"`
 	Symbol          string           `json:"symbol"`
 	Platform        string           `json:"contract_address"`
 	WebSlug         string           `json:"web_slug"`
-	ListingDay      int              `json:"listing_day"`
+	ListingDay      *int             `json:"listing_day"`
+	ListingSource   string           `json:"listing_day_source"`
+	GenesisDate     string           `json:"genesis_date"`
 	MarketData      MarketData       `json:"market_data"`
 	TimePrices      []PricePoint     `json:"timestamp_prices"`
+	Candles         []Candle         `json:"candles,omitempty"`
+	ChartRange      string           `json:"chart_range"`
+	ChartInterval   string           `json:"chart_interval"`
 	LiquidityInfo   DexLiquidityInfo `json:"dex_liquidity_info"`
 	TokenAnalytics  TokenAnalytics   `json:"token_analytics"`
 	Image           Image            `json:"image"`
 	SummaryAnalysis string           `json:"summary_analysis"`
+	SummaryStatus   string           `json:"summary_status"`
+	SummarySource   string           `json:"summary_source,omitempty"` // "ai" or "local"
+	SummaryID       *uuid.UUID       `json:"summary_id,omitempty"`     // summary job of an async lookup
+	Risk            *TokenRisk       `json:"risk,omitempty"`
 }
 
 type MarketData struct {
@@ -31,20 +44,30 @@ type MarketData struct {
 }
 
 type DexLiquidityInfo struct {
-	LiquidityPoolSize float64 `json:"liquidity_pool_size"`
-	TopDex            string  `json:"top_dex"`
-	Volume24h         float64 `json:"volume_24h"`
-	SlippageNote      string  `json:"slippage_note"`
-	DexLiquidityRatio float64 `json:"dex_liquidity_ratio"`
-	LiquidityTrend7D  float64 `json:"liquidity_trend_7d"`
+	LiquidityPoolSize float64            `json:"liquidity_pool_size"`
+	PoolCount         int                `json:"pool_count"`
+	TopDex            string             `json:"top_dex"`
+	TopDexLiquidity   float64            `json:"top_dex_liquidity"`
+	Volume24h         float64            `json:"volume_24h"`
+	SlippageNote      string             `json:"slippage_note"`
+	SlippageEstimates []SlippageEstimate `json:"slippage_estimates"`
+	DexLiquidityRatio float64            `json:"dex_liquidity_ratio"`
+}
+
+// SlippageEstimate is the expected price impact of a trade of a given USD size
+type SlippageEstimate struct {
+	TradeSizeUSD   float64 `json:"trade_size_usd"`
+	PriceImpactPct float64 `json:"price_impact_pct"`
+	Bucket         string  `json:"bucket"`
 }
 type TokenAnalytics struct {
-	TopHolder   float64 `json:"top_holder"`
-	TopWallets  float64 `json:"top_wallets"`
-	TokenViewer float64 `json:"token_viewer"`
-	SniperBot   float64 `json:"sniper_bot"`
-	DevSold     bool    `json:"dev_sold"`
-	DevBuyback  bool    `json:"dev_buyback"`
+	TopHolder     float64 `json:"top_holder"`  // % of supply held by the largest wallet
+	TopWallets    float64 `json:"top_wallets"` // % of supply held by the 10 largest wallets
+	SniperBot     float64 `json:"sniper_bot"`  // % of supply still held by wallets that bought right after creation
+	SniperWallets int     `json:"sniper_wallets"`
+	DevWallet     string  `json:"dev_wallet,omitempty"`
+	DevSold       bool    `json:"dev_sold"`
+	DevBuyback    bool    `json:"dev_buyback"`
 }
 
 // General purpose for USD values
@@ -58,11 +81,53 @@ type PricePoint struct {
 }
 
 type GetPricesRequest struct {
-	Prices [][]float64 `json:"prices"`
+	Prices       [][]float64 `json:"prices"`
+	TotalVolumes [][]float64 `json:"total_volumes"` // rolling 24h volume at each timestamp
+}
+
+// ChartOptions is the price range and series of a lookup
+type ChartOptions struct {
+	Range    string        // 1d, 7d, 30d, 90d, 1y or max
+	Type     string        // "line" or "candles"
+	Interval time.Duration // line sampling interval or candle width
+}
+
+// Candle is an OHLC candle aggregated from the market chart. Volume is the average rolling 24h
+// volume of the candle scaled to its interval.
+type Candle struct {
+	Timestamp string  `json:"timestamp"` // open time
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
 }
 
+// GetLiquidityRequest is a single DEX pair returned by the DexScreener tokens/v1 endpoint
 type GetLiquidityRequest struct {
-	Liquidity CurrencyValue `json:"liquidity"`
+	ChainID       string        `json:"chainId"`
+	DexID         string        `json:"dexId"`
+	PairAddress   string        `json:"pairAddress"`
+	BaseToken     DexToken      `json:"baseToken"`
+	QuoteToken    DexToken      `json:"quoteToken"`
+	PriceUSD      string        `json:"priceUsd"`
+	Volume        DexVolume     `json:"volume"`
+	Liquidity     CurrencyValue `json:"liquidity"`
+	FDV           float64       `json:"fdv"`
+	MarketCap     float64       `json:"marketCap"`
+	PairCreatedAt int64         `json:"pairCreatedAt"` // unix milliseconds
+}
+
+type DexToken struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Symbol  string `json:"symbol"`
+}
+
+type DexVolume struct {
+	H24 float64 `json:"h24"`
+	H6  float64 `json:"h6"`
+	H1  float64 `json:"h1"`
 }
 
 type Image struct {
@@ -78,7 +143,81 @@ type AIResponse struct {
 	AssistantMessage string `json:"assistant_message"`
 }
 
+// ChatCompletionRequest is the body of an OpenAI-compatible chat completions request
+type ChatCompletionRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+}
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatCompletionResponse struct {
+	Choices []struct {
+		Message ChatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// ChatCompletionChunk is one server-sent event of a streamed chat completion
+type ChatCompletionChunk struct {
+	Choices []struct {
+		Delta ChatMessage `json:"delta"`
+	} `json:"choices"`
+}
+
 type SearchRecordResponse struct {
 	ID              uuid.UUID `json:"id"`
 	ContractAddress string    `json:"contract_address"`
 }
+
+// MarketDelta lists the coins of the market stream that changed since the previous event
+type MarketDelta struct {
+	Updated []map[string]interface{} `json:"updated"`
+	Removed []string                 `json:"removed"`
+}
+
+// PriceTick is the latest price, volume and liquidity of a subscribed coin ID or Solana mint
+type PriceTick struct {
+	Asset     string  `json:"asset"`
+	Kind      string  `json:"kind"` // "coin" or "mint"
+	Symbol    string  `json:"symbol"`
+	PriceUSD  float64 `json:"price_usd"`
+	Volume24h float64 `json:"volume_24h"`
+	Liquidity float64 `json:"liquidity"`
+	Timestamp string  `json:"timestamp"`
+}
+
+// PriceSubscriptionMessage is a message sent by a client of the price WebSocket
+type PriceSubscriptionMessage struct {
+	Action  string   `json:"action"` // "subscribe" or "unsubscribe"
+	CoinIDs []string `json:"coin_ids"`
+	Mints   []string `json:"mints"`
+}
+
+// PriceStreamMessage is a message sent to a client of the price WebSocket
+type PriceStreamMessage struct {
+	Type    string      `json:"type"` // "tick", "subscriptions" or "error"
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	CoinIDs []string    `json:"coin_ids,omitempty"`
+	Mints   []string    `json:"mints,omitempty"`
+}
+
+// TokenRisk is the rug risk assessment of a token. Score runs from 0 (no flagged risk) to 100.
+type TokenRisk struct {
+	Score      int        `json:"score"`
+	Level      string     `json:"level"` // "low", "medium", "high" or "critical"
+	Flags      []RiskFlag `json:"flags"`
+	AssessedAt time.Time  `json:"assessed_at"`
+}
+
+// RiskFlag is one risk factor found on a token and the points it adds to the score
+type RiskFlag struct {
+	Code        string `json:"code"`
+	Severity    string `json:"severity"` // "info", "warning" or "danger"
+	Points      int    `json:"points"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+// CursorPage holds the cursors of the pages around a list page, empty when there is no such page.
+// Total is only set when the client asked for it.
+type CursorPage struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// BlockchainSearchList is one page of a user's search history
+type BlockchainSearchList struct {
+	Searches []*BlockchainSearchResponse `json:"searches"`
+	CursorPage
+}
+
+// BlockchainSearchSnapshotList is one page of the stored lookups of a contract address
+type BlockchainSearchSnapshotList struct {
+	Snapshots []*BlockchainSearchSnapshot `json:"snapshots"`
+	CursorPage
+}
+
+// SwapHistory is one page of a user's swaps
+type SwapHistory struct {
+	Swaps []SwapStatusResponse `json:"swaps"`
+	CursorPage
+}
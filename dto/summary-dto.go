@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Summary statuses of a lookup or a summary job
+const (
+	SummaryStatusOK      = "ok"
+	SummaryStatusTimeout = "timeout"
+	SummaryStatusError   = "error"
+	SummaryStatusSkipped = "skipped"
+	SummaryStatusPending = "pending" // generated in the background, poll GET /api/v1/summaries/:id
+)
+
+// Summary modes of a lookup
+const (
+	SummaryModeInline = "inline" // wait for the summary, up to SUMMARY_INLINE_TIMEOUT
+	SummaryModeAsync  = "async"  // return right away and generate the summary in the background
+	SummaryModeSkip   = "skip"   // no summary
+)
+
+// SummaryOptions is how the summary analysis of a lookup is generated
+type SummaryOptions struct {
+	Mode     string
+	Language string
+	Source   string // "ai" or "local", the configured default when empty
+}
+
+// SummaryJob is a summary analysis generated in the background
+type SummaryJob struct {
+	ID          uuid.UUID  `json:"id"`
+	Source      string     `json:"source"`
+	Status      string     `json:"status"`
+	Summary     string     `json:"summary,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
@@ -1,22 +1,74 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type SwapRequest struct {
 	PublicKey  string  `json:"publicKey" binding:"required"`
 	InputMint  string  `json:"inputMint" binding:"required"`
 	OutputMint string  `json:"outputMint" binding:"required"`
 	Amount     float64 `json:"amount" binding:"required"`
+
+	// Optional Jupiter routing parameters
+	SlippageBps        *int     `json:"slippageBps"`        // default 50, cannot be combined with AutoSlippage
+	AutoSlippage       bool     `json:"autoSlippage"`       // let Jupiter pick the slippage
+	MaxAutoSlippageBps *int     `json:"maxAutoSlippageBps"` // upper bound for AutoSlippage
+	OnlyDirectRoutes   bool     `json:"onlyDirectRoutes"`
+	Dexes              []string `json:"dexes"`        // only route through these DEXes
+	ExcludeDexes       []string `json:"excludeDexes"` // never route through these DEXes
+	MaxAccounts        *int     `json:"maxAccounts"`
+
+	// Optional transaction fee parameters, ComputeUnitPriceMicroLamports and PrioritizationFeeLamports are exclusive
+	ComputeUnitPriceMicroLamports *int64 `json:"computeUnitPriceMicroLamports"`
+	PrioritizationFeeLamports     *int64 `json:"prioritizationFeeLamports"`
+	DynamicComputeUnitLimit       bool   `json:"dynamicComputeUnitLimit"`
 }
 
 type SwapResponse struct {
-	Transaction string `json:"transaction"`
+	SwapID      uuid.UUID `json:"swapId"`
+	Transaction string    `json:"transaction"`
 }
 
 type SubmitRequest struct {
-	SignedTransaction string `json:"signedTransaction" binding:"required"`
+	SignedTransaction string     `json:"signedTransaction" binding:"required"`
+	SwapID            *uuid.UUID `json:"swapId" binding:"required"` // swap returned by POST /swaps
+	Simulate          bool       `json:"simulate"`                  // simulate the transaction and reject it when it would fail
 }
 
 type SubmitResponse struct {
-	Signature string `json:"signature"`
+	SwapID     uuid.UUID         `json:"swapId"`
+	Signature  string            `json:"signature,omitempty"`
+	Status     string            `json:"status"`
+	Simulation *SimulationResult `json:"simulation,omitempty"`
+}
+
+// SimulationResult is the outcome of simulating a transaction, Err is nil when it would succeed
+type SimulationResult struct {
+	Err           interface{} `json:"err"`
+	Logs          []string    `json:"logs"`
+	UnitsConsumed *uint64     `json:"unitsConsumed,omitempty"`
+}
+
+// SwapStatusResponse is a tracked swap and its on-chain confirmation status
+type SwapStatusResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	PublicKey       string     `json:"public_key"`
+	InputMint       string     `json:"input_mint"`
+	OutputMint      string     `json:"output_mint"`
+	InAmount        float64    `json:"in_amount"`
+	OutAmount       float64    `json:"out_amount"`
+	MinimumReceived float64    `json:"minimum_received"`
+	SlippageBps     int        `json:"slippage_bps"`
+	Signature       *string    `json:"signature"`
+	Status          string     `json:"status"`
+	Slot            uint64     `json:"slot,omitempty"`
+	Error           *string    `json:"error,omitempty"`
+	SubmittedAt     *time.Time `json:"submitted_at,omitempty"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
 }
 
 type JupiterErrorResponse struct {
@@ -30,17 +82,47 @@ type GetCurrencySwapResponse struct {
 	SwapUsdValue    float64 `json:"swap_usd_value"`
 	IsSwappable     bool    `json:"is_swappable"`
 	BalanceInAmount float64 `json:"balance_in_amount"`
+
+	PriceImpactPct   float64    `json:"price_impact_pct"`
+	PriceImpactLevel string     `json:"price_impact_level"` // "low", "medium" or "high"
+	SlippageBps      int        `json:"slippage_bps"`
+	MinimumReceived  float64    `json:"minimum_received"` // OutAmount after the worst accepted slippage
+	EffectivePrice   float64    `json:"effective_price"`  // output tokens received per input token
+	Route            []RouteHop `json:"route"`
+	ContextSlot      uint64     `json:"context_slot"`
+	QuotedAt         time.Time  `json:"quoted_at"`
+	QuoteExpiresAt   time.Time  `json:"quote_expires_at"`
+}
+
+// RouteHop is one AMM swap of a Jupiter route. Amounts are raw integer strings in the base units
+// of their mint, divide them by 10^decimals for UI units. Decimals are null for unknown mints.
+type RouteHop struct {
+	Percent     float64 `json:"percent"`
+	Label       string  `json:"label"`
+	AmmKey      string  `json:"amm_key"`
+	InputMint   string  `json:"input_mint"`
+	OutputMint  string  `json:"output_mint"`
+	InAmount    string  `json:"in_amount"`
+	InDecimals  *int    `json:"in_decimals"`
+	OutAmount   string  `json:"out_amount"`
+	OutDecimals *int    `json:"out_decimals"`
+	FeeAmount   string  `json:"fee_amount"`
+	FeeMint     string  `json:"fee_mint"`
+	FeeDecimals *int    `json:"fee_decimals"`
 }
 
 type QuoteResponse struct {
-	InputMint      string       `json:"inputMint"`
-	OutputMint     string       `json:"outputMint"`
-	InAmount       string       `json:"inAmount"`
-	OutAmount      string       `json:"outAmount"`
-	SlippageBps    int          `json:"slippageBps"`
-	PriceImpactPct string       `json:"priceImpactPct"`
-	SwapUsdValue   string       `json:"swapUsdValue"`
-	RoutePlan      []RouteEntry `json:"routePlan"`
+	InputMint            string       `json:"inputMint"`
+	OutputMint           string       `json:"outputMint"`
+	InAmount             string       `json:"inAmount"`
+	OutAmount            string       `json:"outAmount"`
+	OtherAmountThreshold string       `json:"otherAmountThreshold"`
+	SwapMode             string       `json:"swapMode"`
+	SlippageBps          int          `json:"slippageBps"`
+	PriceImpactPct       string       `json:"priceImpactPct"`
+	SwapUsdValue         string       `json:"swapUsdValue"`
+	RoutePlan            []RouteEntry `json:"routePlan"`
+	ContextSlot          uint64       `json:"contextSlot"`
 }
 
 type RouteEntry struct {
@@ -49,10 +131,12 @@ type RouteEntry struct {
 }
 
 type SwapInfo struct {
-	Label     string `json:"label"`
-	AmmKey    string `json:"ammKey"`
-	InAmount  string `json:"inAmount"`
-	OutAmount string `json:"outAmount"`
-	FeeAmount string `json:"feeAmount"`
-	FeeMint   string `json:"feeMint"`
+	Label      string `json:"label"`
+	AmmKey     string `json:"ammKey"`
+	InputMint  string `json:"inputMint"`
+	OutputMint string `json:"outputMint"`
+	InAmount   string `json:"inAmount"`
+	OutAmount  string `json:"outAmount"`
+	FeeAmount  string `json:"feeAmount"`
+	FeeMint    string `json:"feeMint"`
 }
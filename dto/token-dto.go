@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/datatypes"
@@ -22,9 +23,21 @@ type TokenDTO struct {
 	Tags              datatypes.JSON `json:"tags"`
 }
 
+// TokenFilter narrows and orders the token list, nil fields are not filtered on
+type TokenFilter struct {
+	Search             string
+	Address            string
+	Tags               []string // tokens must carry every tag
+	HasFreezeAuthority *bool
+	HasMintAuthority   *bool
+	MinDailyVolume     *float64
+	SortBy             string // "relevance", "volume", "name", "minted_at" or "id"
+	SortOrder          string // "asc" or "desc"
+}
+
 type TokenResponse struct {
 	Tokens []*TokenDTO `json:"tokens"`
-	Total  int64       `json:"total"`
+	CursorPage
 }
 
 type TokenAccountsResponse struct {
@@ -34,6 +47,43 @@ type TokenAccountsResponse struct {
 	Symbol   string `json:"symbol"`
 	Name     string `json:"name"`
 	Decimals int    `json:"decimals"`
+
+	// Token program owning the mint, "spl-token" or "spl-token-2022"
+	Program           string         `json:"program"`
+	TransferFee       *TransferFee   `json:"transfer_fee,omitempty"`
+	PermanentDelegate *string        `json:"permanent_delegate,omitempty"`
+	Extensions        datatypes.JSON `json:"extensions,omitempty"`
+}
+
+// TransferFee is the Token-2022 transfer fee of a mint, effective from Epoch
+type TransferFee struct {
+	BasisPoints int    `json:"basis_points"`
+	MaximumFee  uint64 `json:"maximum_fee"` // raw amount
+	Epoch       uint64 `json:"epoch"`
+}
+
+// MintAccountsResult is the jsonParsed result of getMultipleAccounts for mint accounts
+type MintAccountsResult struct {
+	Value []*MintAccount `json:"value"`
+}
+
+type MintAccount struct {
+	Data struct {
+		Program string `json:"program"`
+		Parsed  struct {
+			Info struct {
+				Decimals   int             `json:"decimals"`
+				Extensions []MintExtension `json:"extensions"`
+			} `json:"info"`
+			Type string `json:"type"`
+		} `json:"parsed"`
+	} `json:"data"`
+}
+
+// MintExtension is one Token-2022 extension of a mint, State depends on the extension type
+type MintExtension struct {
+	Extension string          `json:"extension"`
+	State     json.RawMessage `json:"state"`
 }
 
 type TokenAccountsApiResponse struct {
@@ -101,3 +151,55 @@ type TokenAmountDetail struct {
 	UIAmount       float64 `json:"uiAmount"`
 	UIAmountString string  `json:"uiAmountString"`
 }
+
+// TokenPrice is the USD price of a token, Change24h is a percentage and nil when the source does not report it
+type TokenPrice struct {
+	PriceUSD  float64  `json:"price_usd"`
+	Change24h *float64 `json:"change_24h"`
+	Source    string   `json:"source"`
+}
+
+// PortfolioResponse is the USD valuation of every holding of a wallet
+type PortfolioResponse struct {
+	Wallet         string             `json:"wallet"`
+	TotalValueUSD  float64            `json:"total_value_usd"`
+	Change24hUSD   float64            `json:"change_24h_usd"`
+	Change24hPct   *float64           `json:"change_24h_pct"`
+	Holdings       []PortfolioHolding `json:"holdings"`
+	UnpricedTokens int                `json:"unpriced_tokens"`
+	PricedAt       time.Time          `json:"priced_at"`
+}
+
+// PortfolioHolding is one token of a wallet, AllocationPct is its share of the total portfolio value
+type PortfolioHolding struct {
+	Address       string   `json:"address"`
+	Symbol        string   `json:"symbol"`
+	Name          string   `json:"name"`
+	LogoURI       string   `json:"logoURI"`
+	Program       string   `json:"program"`
+	Decimals      int      `json:"decimals"`
+	RawAmount     string   `json:"raw_amount"`
+	Amount        float64  `json:"amount"`
+	PriceUSD      *float64 `json:"price_usd"`
+	PriceSource   string   `json:"price_source,omitempty"`
+	ValueUSD      float64  `json:"value_usd"`
+	Change24h     *float64 `json:"change_24h"`
+	AllocationPct float64  `json:"allocation_pct"`
+}
+
+// JupiterToken is one entry of the Jupiter token list
+type JupiterToken struct {
+	Address           string          `json:"address"`
+	Name              string          `json:"name"`
+	Symbol            string          `json:"symbol"`
+	Decimals          int             `json:"decimals"`
+	LogoURI           string          `json:"logoURI"`
+	Tags              json.RawMessage `json:"tags"`
+	DailyVolume       *float64        `json:"daily_volume"`
+	CreatedAt         *time.Time      `json:"created_at"`
+	FreezeAuthority   *string         `json:"freeze_authority"`
+	MintAuthority     *string         `json:"mint_authority"`
+	PermanentDelegate *string         `json:"permanent_delegate"`
+	MintedAt          *time.Time      `json:"minted_at"`
+	Extensions        json.RawMessage `json:"extensions"`
+}
@@ -7,13 +7,14 @@ import (
 	"github.com/google/uuid"
 )
 
-// BlockchainSearch merepresentasikan riwayat pencarian kontrak blockchain
+// BlockchainSearch merepresentasikan riwayat pencarian kontrak blockchain.
+// Setiap pencarian disimpan sebagai snapshot baru sehingga perubahan token antar pencarian dapat dibandingkan.
 type BlockchainSearch struct {
 	ID              uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	UserID          uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
-	ContractAddress string          `gorm:"not null;index" json:"contract_address"`
+	UserID          uuid.UUID       `gorm:"type:uuid;not null;index;index:idx_search_user_contract,priority:1" json:"user_id"`
+	ContractAddress string          `gorm:"not null;index;index:idx_search_user_contract,priority:2" json:"contract_address"`
 	ResponseData    json.RawMessage `gorm:"type:jsonb" json:"response_data"`
-	CreatedAt       time.Time       `json:"created_at"`
+	CreatedAt       time.Time       `gorm:"index:idx_search_user_contract,priority:3" json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 
 	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// CacheEntry merepresentasikan respons upstream yang di-cache bersama di Postgres
+type CacheEntry struct {
+	Key       string    `gorm:"primaryKey;size:512"` // key yang lebih panjang disimpan sebagai digest SHA-256
+	Value     []byte    `gorm:"type:bytea;not null"`
+	StoredAt  time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken merepresentasikan refresh token yang disimpan di server.
+// Token asli hanya dikirim ke klien, server hanya menyimpan hash SHA-256-nya.
+// Token yang dirotasi membentuk satu family sehingga penggunaan ulang token lama dapat mencabut seluruh family.
+type RefreshToken struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	FamilyID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	TokenHash    string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	RevokedAt    *time.Time
+	ReplacedByID *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt    time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// IsActive memeriksa apakah refresh token belum dicabut dan belum kedaluwarsa
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// RevokedAccessToken merepresentasikan access token (berdasarkan jti) yang dicabut sebelum kedaluwarsa
+type RevokedAccessToken struct {
+	JTI       string    `gorm:"primaryKey;size:64"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// NewRefreshTokenValue membuat nilai refresh token acak beserta hash yang disimpan di database
+func NewRefreshTokenValue() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(buf)
+	return value, HashRefreshToken(value), nil
+}
+
+// HashRefreshToken menghasilkan hash SHA-256 dari nilai refresh token
+func HashRefreshToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status transaksi swap dari pembuatan hingga konfirmasi di chain
+const (
+	SwapStatusCreated   = "created"
+	SwapStatusSubmitted = "submitted"
+	SwapStatusConfirmed = "confirmed"
+	SwapStatusFinalized = "finalized"
+	SwapStatusFailed    = "failed"
+	SwapStatusExpired   = "expired"
+)
+
+// Swap merepresentasikan transaksi swap Jupiter milik user.
+// Record dibuat saat transaksi disusun, lalu signature dan statusnya diperbarui setelah transaksi dikirim.
+type Swap struct {
+	ID              uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID          uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	PublicKey       string          `gorm:"not null" json:"public_key"`
+	InputMint       string          `json:"input_mint"`
+	OutputMint      string          `json:"output_mint"`
+	InAmount        float64         `json:"in_amount"`
+	OutAmount       float64         `json:"out_amount"`
+	MinimumReceived float64         `json:"minimum_received"`
+	SlippageBps     int             `json:"slippage_bps"`
+	Quote           json.RawMessage `gorm:"type:jsonb" json:"quote,omitempty"`
+	Signature       *string         `gorm:"uniqueIndex" json:"signature"`
+	Status          string          `gorm:"not null;index" json:"status"`
+	Slot            uint64          `json:"slot,omitempty"`
+	Error           *string         `json:"error,omitempty"`
+	SubmittedAt     *time.Time      `json:"submitted_at,omitempty"`
+	ConfirmedAt     *time.Time      `json:"confirmed_at,omitempty"`
+	CreatedAt       time.Time       `gorm:"index" json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// IsPending memeriksa apakah swap masih menunggu hasil akhir dari chain
+func (s *Swap) IsPending() bool {
+	return s.Status == SwapStatusSubmitted || s.Status == SwapStatusConfirmed
+}
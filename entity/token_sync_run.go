@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status sinkronisasi daftar token
+const (
+	TokenSyncStatusRunning   = "running"
+	TokenSyncStatusSucceeded = "succeeded"
+	TokenSyncStatusFailed    = "failed"
+)
+
+// TokenSyncRun merepresentasikan satu kali proses impor daftar token ke tabel tokens
+type TokenSyncRun struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Source         string     `gorm:"not null" json:"source"`
+	Status         string     `gorm:"not null;index" json:"status"`
+	TokensFetched  int        `json:"tokens_fetched"`
+	TokensUpserted int        `json:"tokens_upserted"`
+	Error          *string    `json:"error,omitempty"`
+	StartedAt      time.Time  `gorm:"not null;index" json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
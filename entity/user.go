@@ -2,6 +2,7 @@ package entity
 
 import (
 	"blockchain-scrap/pkg/errs"
+	"errors"
 	"log"
 	"os"
 	"strings"
@@ -22,6 +23,10 @@ type User struct {
 	BlockchainSearches []BlockchainSearch `gorm:"foreignKey:UserID"`
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
+
+	// TokenID dan TokenExpiresAt diisi dari klaim jti dan exp saat token divalidasi
+	TokenID        string    `gorm:"-"`
+	TokenExpiresAt time.Time `gorm:"-"`
 }
 
 // HashPassword mengenkripsi password pengguna menggunakan bcrypt
@@ -46,25 +51,31 @@ func (u *User) ComparePassword(inputPassword string) errs.MessageErr {
 	return nil
 }
 
-// CreateToken membuat JWT token untuk autentikasi
-func (u *User) CreateToken() (string, errs.MessageErr) {
+// CreateToken membuat JWT access token untuk autentikasi yang berlaku selama ttl
+func (u *User) CreateToken(ttl time.Duration) (string, time.Time, errs.MessageErr) {
 	jwtSecret := os.Getenv("JWT_SECRET")
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(ttl)
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		jwt.MapClaims{
 			"id":    u.ID,
 			"email": u.Email,
+			"iat":   issuedAt.Unix(),
+			"exp":   expiresAt.Unix(),
+			"jti":   uuid.NewString(),
 		})
 
 	signedToken, err := token.SignedString([]byte(jwtSecret))
 	if err != nil {
 		log.Println("Error saat menandatangani token:", err.Error())
-		return "", errs.NewInternalServerError("Gagal menandatangani token JWT")
+		return "", time.Time{}, errs.NewInternalServerError("Gagal menandatangani token JWT")
 	}
 
-	return signedToken, nil
+	return signedToken, expiresAt, nil
 }
 
-// ParseToken memvalidasi dan mengurai token JWT
+// ParseToken memvalidasi dan mengurai token JWT, termasuk masa berlakunya
 func (u *User) ParseToken(tokenString string) (*jwt.Token, errs.MessageErr) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
@@ -72,8 +83,11 @@ func (u *User) ParseToken(tokenString string) (*jwt.Token, errs.MessageErr) {
 			return nil, errs.NewUnauthenticated("Metode token tidak valid")
 		}
 		return []byte(jwtSecret), nil
-	})
+	}, jwt.WithExpirationRequired(), jwt.WithIssuedAt())
 	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errs.NewUnauthenticated("Token sudah kedaluwarsa")
+		}
 		return nil, errs.NewUnauthenticated("Token tidak valid")
 	}
 
@@ -109,6 +123,7 @@ func (u *User) ValidateToken(bearerToken string) errs.MessageErr {
 func (u *User) bindTokenToUserEntity(claims jwt.MapClaims) errs.MessageErr {
 	userID, hasID := claims["id"].(string)
 	userEmail, hasEmail := claims["email"].(string)
+	tokenID, hasTokenID := claims["jti"].(string)
 
 	if !hasID {
 		return errs.NewUnauthenticated("Token tidak mengandung ID")
@@ -118,13 +133,24 @@ func (u *User) bindTokenToUserEntity(claims jwt.MapClaims) errs.MessageErr {
 		return errs.NewUnauthenticated("Token tidak mengandung email")
 	}
 
-	parsedUUID, err := uuid.Parse(userID)
-	if err != nil {
+	if !hasTokenID {
+		return errs.NewUnauthenticated("Token tidak mengandung jti")
+	}
+
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return errs.NewUnauthenticated("Token tidak mengandung exp")
+	}
+
+	parsedUUID, errParse := uuid.Parse(userID)
+	if errParse != nil {
 		return errs.NewBadRequest("ID tidak valid")
 	}
 
 	u.ID = parsedUUID
 	u.Email = userEmail
+	u.TokenID = tokenID
+	u.TokenExpiresAt = expiresAt.Time
 
 	return nil
 }
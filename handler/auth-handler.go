@@ -2,6 +2,7 @@ package handler
 
 import (
 	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
 	"blockchain-scrap/service"
 	"net/http"
 
@@ -74,3 +75,62 @@ func (h *UserHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, token)
 }
+
+// Refresh handles access token refresh requests
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Rotate a refresh token and get a new access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Refresh Request"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/auth/refresh [post]
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	token, err := h.userSvc.Refresh(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// Logout handles user logout requests
+// Logout godoc
+// @Summary User logout
+// @Description Revoke the current access token and, when given, the refresh token session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LogoutRequest false "Logout Request"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+
+	userData := c.MustGet("userData").(*entity.User)
+	if err := h.userSvc.Logout(c.Request.Context(), userData, req); err != nil {
+		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
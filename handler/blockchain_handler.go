@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"blockchain-scrap/dto"
 	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/cache"
+	"blockchain-scrap/pkg/config"
 	"blockchain-scrap/pkg/errs"
+	"blockchain-scrap/pkg/prompt"
 	"blockchain-scrap/service"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -14,11 +20,12 @@ import (
 // BlockchainHandler handles blockchain data requests
 type BlockchainHandler struct {
 	blockchainSvc service.BlockchainService
+	marketHub     service.MarketStreamHub
 }
 
 // NewBlockchainHandler creates a new instance of BlockchainHandler
-func NewBlockchainHandler(svc service.BlockchainService) *BlockchainHandler {
-	return &BlockchainHandler{blockchainSvc: svc}
+func NewBlockchainHandler(svc service.BlockchainService, marketHub service.MarketStreamHub) *BlockchainHandler {
+	return &BlockchainHandler{blockchainSvc: svc, marketHub: marketHub}
 }
 
 // GetBlockchainDetailByContractAddress gets blockchain details by contract address
@@ -29,29 +36,33 @@ func NewBlockchainHandler(svc service.BlockchainService) *BlockchainHandler {
 // @Accept json
 // @Produce json
 // @Param contract-address path string true "Contract Address"
-// @Param time-skip query string false "Time interval for data (default: 5m)" default(5m)
+// @Param range query string false "Price range: 1d, 7d, 30d, 90d, 1y or max (default: 1d)" default(1d)
+// @Param chart query string false "Chart series: line or candles (default: line)" default(line)
+// @Param time-skip query string false "Line sampling interval or candle width (default: 5m for 1d, 1h for 7d, 4h for 30d, 12h for 90d, 24h for 1y, 168h for max)"
+// @Param lang query string false "Language of the summary analysis (default: SUMMARY_LANGUAGE or en)"
+// @Param summary query string false "Summary mode: inline, async or skip (default: inline). Async summaries are skipped while SUMMARY_MAX_JOBS are running" default(inline)
+// @Param summary_source query string false "Summary source: ai or local (default: SUMMARY_SOURCE, local without an AI backend)"
 // @Success 200 {object} dto.ContractAddressResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /coins/v2/{contract-address} [get]
 func (h *BlockchainHandler) GetBlockchainDetailByContractAddress(c *gin.Context) {
 	contractAddress := c.Param("contract-address")
-	timeSkipStr := c.DefaultQuery("time-skip", "5m")
-	const minTimeSkip = 5 * time.Minute
-	//userData := c.MustGet("userData").(*entity.User)
-
-	timeSkip, err := time.ParseDuration(timeSkipStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_skip format. Use formats like 30s, 5m, 1h."})
+	chart, errChart := chartOptions(c)
+	if errChart != nil {
+		c.JSON(errChart.StatusCode(), gin.H{"error": errChart.Message()})
 		return
 	}
 
-	if timeSkip < minTimeSkip {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Time interval must be more than 4 minutes"})
+	summary, ok := summaryOptions(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid summary options. Use summary=inline, async or skip and summary_source=ai or local."})
 		return
 	}
 
-	result, errService := h.blockchainSvc.GetBlockchainDetailByContractAddress(c.Request.Context(), contractAddress, timeSkip)
+	ctx, tracker := cache.WithTracker(c.Request.Context())
+	result, errService := h.blockchainSvc.GetBlockchainDetailByContractAddress(ctx, optionalUserID(c), contractAddress, chart, summary)
+	writeCacheHeaders(c, tracker)
 	if errService != nil {
 
 		c.JSON(errService.StatusCode(), gin.H{"error": errService.Message()})
@@ -70,7 +81,12 @@ func (h *BlockchainHandler) GetBlockchainDetailByContractAddress(c *gin.Context)
 // @Produce json
 // @Param blockchain-id path string true "Blockchain ID"
 // @Param contract-address path string true "Contract Address"
-// @Param time-skip query string false "Time interval for data (default: 5m)" default(5m)
+// @Param range query string false "Price range: 1d, 7d, 30d, 90d, 1y or max (default: 1d)" default(1d)
+// @Param chart query string false "Chart series: line or candles (default: line)" default(line)
+// @Param time-skip query string false "Line sampling interval or candle width (default: 5m for 1d, 1h for 7d, 4h for 30d, 12h for 90d, 24h for 1y, 168h for max)"
+// @Param lang query string false "Language of the summary analysis (default: SUMMARY_LANGUAGE or en)"
+// @Param summary query string false "Summary mode: inline, async or skip (default: inline). Async summaries are skipped while SUMMARY_MAX_JOBS are running" default(inline)
+// @Param summary_source query string false "Summary source: ai or local (default: SUMMARY_SOURCE, local without an AI backend)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -78,27 +94,23 @@ func (h *BlockchainHandler) GetBlockchainDetailByContractAddress(c *gin.Context)
 func (h *BlockchainHandler) GetBlockchainDetailByIDAndContractAddress(c *gin.Context) {
 	contractAddress := c.Param("contract-address")
 	blockchainID := c.Param("blockchain-id")
-	timeSkipStr := c.DefaultQuery("time-skip", "5m")
-	const minTimeSkip = 5 * time.Minute
-
-	timeSkip, err := time.ParseDuration(timeSkipStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_skip format. Use formats like 30s, 5m, 1h."})
+	chart, errChart := chartOptions(c)
+	if errChart != nil {
+		c.JSON(errChart.StatusCode(), gin.H{"error": errChart.Message()})
 		return
 	}
 
-	if timeSkip < minTimeSkip {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Time interval must be more than 4 minutes"})
+	summary, ok := summaryOptions(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid summary options. Use summary=inline, async or skip and summary_source=ai or local."})
 		return
 	}
 
-	result, err := h.blockchainSvc.GetBlockchainDetailByContractAddressAndID(blockchainID, contractAddress, timeSkip)
+	ctx, tracker := cache.WithTracker(c.Request.Context())
+	result, err := h.blockchainSvc.GetBlockchainDetailByContractAddressAndID(ctx, blockchainID, contractAddress, chart, summary)
+	writeCacheHeaders(c, tracker)
 	if err != nil {
-		if messageErr, ok := err.(errs.MessageErr); ok {
-			c.JSON(messageErr.StatusCode(), gin.H{"error": messageErr.Message()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error occurred"})
-		}
+		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
 		return
 	}
 	c.JSON(http.StatusOK, result)
@@ -115,7 +127,9 @@ func (h *BlockchainHandler) GetBlockchainDetailByIDAndContractAddress(c *gin.Con
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/blockchains [get]
 func (h *BlockchainHandler) GetAllBlockchains(c *gin.Context) {
-	result, err := h.blockchainSvc.GetAllBlockchains()
+	ctx, tracker := cache.WithTracker(c.Request.Context())
+	result, err := h.blockchainSvc.GetAllBlockchains(ctx)
+	writeCacheHeaders(c, tracker)
 	if err != nil {
 		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
 		return
@@ -126,33 +140,71 @@ func (h *BlockchainHandler) GetAllBlockchains(c *gin.Context) {
 // GetAllBlockchainSearchesByUserID gets all search history by user ID
 // GetAllBlockchainSearchesByUserID godoc
 // @Summary Get all blockchain searches by user ID
-// @Description Get search history for a specific user
+// @Description Get search history for a specific user, newest first
 // @Tags blockchain
 // @Accept json
 // @Produce json
-// @Success 200 {array} map[string]interface{}
+// @Param limit query int false "Number of items per page (default: 10, max: 100)"
+// @Param cursor query string false "Cursor of the page to read"
+// @Param include_total query bool false "Count the searches"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/searches [get]
 func (h *BlockchainHandler) GetAllBlockchainSearchesByUserID(c *gin.Context) {
 	userData := c.MustGet("userData").(*entity.User)
-	result, err := h.blockchainSvc.FindByUserID(c.Request.Context(), userData.ID)
+	page := parsePage(c)
+	result, err := h.blockchainSvc.FindByUserID(c.Request.Context(), userData.ID, page)
 	if err != nil {
 		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
 		return
 	}
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, gin.H{
+		"data":       result.Searches,
+		"pagination": paginationBody(c, page, result.CursorPage),
+	})
+}
+
+// GetBlockchainSearchSnapshotsByContract gets every stored lookup of a contract address by the user
+// GetBlockchainSearchSnapshotsByContract godoc
+// @Summary Get search snapshots by contract address
+// @Description Get the stored lookups of a contract address by the authenticated user, newest first
+// @Tags blockchain
+// @Accept json
+// @Produce json
+// @Param contract-address path string true "Contract Address"
+// @Param limit query int false "Number of items per page (default: 10, max: 100)"
+// @Param cursor query string false "Cursor of the page to read"
+// @Param include_total query bool false "Count the snapshots"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/searches/contracts/{contract-address} [get]
+func (h *BlockchainHandler) GetBlockchainSearchSnapshotsByContract(c *gin.Context) {
+	userData := c.MustGet("userData").(*entity.User)
+	page := parsePage(c)
+	result, err := h.blockchainSvc.FindSnapshotsByContract(c.Request.Context(), userData.ID, c.Param("contract-address"), page)
+	if err != nil {
+		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":       result.Snapshots,
+		"pagination": paginationBody(c, page, result.CursorPage),
+	})
 }
 
 // GetBlockchainSearchByID gets search history by ID
 // GetBlockchainSearchByID godoc
 // @Summary Get blockchain search by ID
-// @Description Get specific search history by search ID
+// @Description Get specific search history of the authenticated user by search ID
 // @Tags blockchain
 // @Accept json
 // @Produce json
 // @Param search-id path string true "Search ID (UUID)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/searches/{search-id} [get]
 func (h *BlockchainHandler) GetBlockchainSearchByID(c *gin.Context) {
@@ -164,7 +216,8 @@ func (h *BlockchainHandler) GetBlockchainSearchByID(c *gin.Context) {
 		return
 	}
 
-	result, err := h.blockchainSvc.FindByID(c.Request.Context(), searchUUID)
+	userData := c.MustGet("userData").(*entity.User)
+	result, err := h.blockchainSvc.FindByID(c.Request.Context(), userData.ID, searchUUID)
 	if err != nil {
 		if messageErr, ok := err.(errs.MessageErr); ok {
 			c.JSON(messageErr.StatusCode(), gin.H{"error": messageErr.Message()})
@@ -179,38 +232,197 @@ func (h *BlockchainHandler) GetBlockchainSearchByID(c *gin.Context) {
 // StreamBlockchains streams blockchain data in real-time using Server-Sent Events
 // StreamBlockchains godoc
 // @Summary Stream blockchain data
-// @Description Stream real-time blockchain data using Server-Sent Events
+// @Description Stream real-time blockchain data using Server-Sent Events. The first event is a
+// @Description "snapshot" of the full list, followed by "delta" events with only the changed coins.
+// @Description Reconnect with the Last-Event-ID header to resume from the last received event.
 // @Tags blockchain
 // @Accept json
 // @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/blockchains/stream [get]
 func (h *BlockchainHandler) StreamBlockchains(c *gin.Context) {
+	lastEventID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
 	c.Writer.Flush()
 
-	ticker := time.NewTicker(7 * time.Second)
-	defer ticker.Stop()
+	initial, events, unsubscribe := h.marketHub.Subscribe(lastEventID)
+	defer unsubscribe()
 
-	sendBlockchainData := func() {
-		blockchains, err := h.blockchainSvc.GetAllBlockchains()
-		if err != nil {
-			c.SSEvent("error", gin.H{"error": err.Message()})
-			return
-		}
-		c.SSEvent("message", blockchains)
+	for _, event := range initial {
+		writeMarketEvent(c, event)
 	}
 
-	sendBlockchainData()
 	for {
 		select {
 		case <-c.Request.Context().Done():
 			return
-		case <-ticker.C:
-			sendBlockchainData()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			writeMarketEvent(c, event)
+		}
+	}
+}
+
+// StreamBlockchainDetailByContractAddress streams a contract lookup and its summary using Server-Sent Events
+// StreamBlockchainDetailByContractAddress godoc
+// @Summary Stream blockchain details and summary by contract address
+// @Description Stream a token lookup using Server-Sent Events. A "snapshot" event with the market data is
+// @Description sent as soon as it is ready, followed by "summary" events with pieces of the summary analysis
+// @Description as they are generated and a final "done" event with the summary status and full text.
+// @Description Backends that cannot stream (Athenor and the local summary) send the whole summary in a single
+// @Description "summary" event, the "done" event reports them with "streaming": false.
+// @Tags blockchain
+// @Accept json
+// @Produce text/event-stream
+// @Param contract-address path string true "Contract Address"
+// @Param range query string false "Price range: 1d, 7d, 30d, 90d, 1y or max (default: 1d)" default(1d)
+// @Param chart query string false "Chart series: line or candles (default: line)" default(line)
+// @Param time-skip query string false "Line sampling interval or candle width (default: 5m for 1d, 1h for 7d, 4h for 30d, 12h for 90d, 24h for 1y, 168h for max)"
+// @Param lang query string false "Language of the summary analysis (default: SUMMARY_LANGUAGE or en)"
+// @Param summary query string false "Summary mode, only inline is supported" default(inline)
+// @Param summary_source query string false "Summary source: ai or local (default: SUMMARY_SOURCE, local without an AI backend)"
+// @Success 200 {object} dto.ContractAddressResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /coins/v2/{contract-address}/stream [get]
+func (h *BlockchainHandler) StreamBlockchainDetailByContractAddress(c *gin.Context) {
+	contractAddress := c.Param("contract-address")
+	chart, errChart := chartOptions(c)
+	if errChart != nil {
+		c.JSON(errChart.StatusCode(), gin.H{"error": errChart.Message()})
+		return
+	}
+
+	// The summary is always streamed inline, skip and async are only available on the regular lookup
+	summary, ok := summaryOptions(c)
+	if !ok || summary.Mode != dto.SummaryModeInline {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid summary options. Streams only support summary=inline and summary_source=ai or local."})
+		return
+	}
+
+	ctx, tracker := cache.WithTracker(c.Request.Context())
+	streaming, summaryStreaming := false, false
+	snapshot := func(response *dto.ContractAddressResponse, streams bool) {
+		writeCacheHeaders(c, tracker)
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.Header().Set("Cache-Control", "no-cache")
+		c.Writer.Header().Set("Connection", "keep-alive")
+		streaming, summaryStreaming = true, streams
+		writeStreamEvent(c, "snapshot", response)
+	}
+	chunk := func(text string) {
+		writeStreamEvent(c, "summary", gin.H{"delta": text})
+	}
+
+	result, errService := h.blockchainSvc.StreamBlockchainDetailByContractAddress(ctx, optionalUserID(c), contractAddress, chart, summary, snapshot, chunk)
+	if errService != nil {
+		if streaming {
+			writeStreamEvent(c, "error", gin.H{"error": errService.Message()})
+			return
+		}
+		c.JSON(errService.StatusCode(), gin.H{"error": errService.Message()})
+		return
+	}
+
+	writeStreamEvent(c, "done", gin.H{
+		"summary_status":   result.SummaryStatus,
+		"summary_source":   result.SummarySource,
+		"summary_analysis": result.SummaryAnalysis,
+		"streaming":        summaryStreaming,
+	})
+}
+
+// writeStreamEvent writes an SSE event and flushes it
+func writeStreamEvent(c *gin.Context, event string, data interface{}) {
+	c.Render(-1, sse.Event{Event: event, Data: data})
+	c.Writer.Flush()
+}
+
+// writeMarketEvent writes a market stream event to the SSE response and flushes it
+func writeMarketEvent(c *gin.Context, event service.MarketEvent) {
+	sseEvent := sse.Event{Event: event.Type, Data: event.Data}
+	if event.ID > 0 {
+		sseEvent.Id = strconv.FormatUint(event.ID, 10)
+	}
+	c.Render(-1, sseEvent)
+	c.Writer.Flush()
+}
+
+// optionalUserID returns the ID of the authenticated user, or uuid.Nil for anonymous requests
+func optionalUserID(c *gin.Context) uuid.UUID {
+	if userData, ok := c.Get("userData"); ok {
+		if user, ok := userData.(*entity.User); ok {
+			return user.ID
 		}
 	}
+	return uuid.Nil
+}
+
+// writeCacheHeaders exposes how the upstream market data of a request was served:
+// X-Cache is HIT, MISS or PARTIAL and X-Cache-Age is the age in seconds of the oldest cached value
+func writeCacheHeaders(c *gin.Context, tracker *cache.Tracker) {
+	status, age := tracker.Summary()
+	if status == "" {
+		return
+	}
+	c.Header("X-Cache", status)
+	if status != string(cache.StatusMiss) {
+		c.Header("X-Cache-Age", strconv.Itoa(int(age.Seconds())))
+	}
+}
+
+// GetTokenRisk gets the rug risk assessment of a Solana token
+// GetTokenRisk godoc
+// @Summary Get token risk score
+// @Description Score the rug risk of a Solana token from its authorities, extensions, age, volume, liquidity and holder concentration
+// @Tags blockchain
+// @Accept json
+// @Produce json
+// @Param address path string true "Token mint address"
+// @Success 200 {object} dto.TokenRisk
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tokens/{address}/risk [get]
+func (h *BlockchainHandler) GetTokenRisk(c *gin.Context) {
+	ctx, tracker := cache.WithTracker(c.Request.Context())
+	result, err := h.blockchainSvc.GetTokenRisk(ctx, c.Param("address"))
+	writeCacheHeaders(c, tracker)
+	if err != nil {
+		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// chartOptions reads the price range, series and interval of a lookup from the range, chart and
+// time-skip query parameters
+func chartOptions(c *gin.Context) (dto.ChartOptions, errs.MessageErr) {
+	return service.NewChartOptions(c.Query("range"), c.Query("chart"), c.Query("time-skip"))
+}
+
+// summaryOptions reads the summary mode, language and source of a lookup from the summary, lang and
+// summary_source query parameters
+func summaryOptions(c *gin.Context) (dto.SummaryOptions, bool) {
+	options := dto.SummaryOptions{
+		Mode:     strings.ToLower(c.DefaultQuery("summary", dto.SummaryModeInline)),
+		Language: strings.ToLower(c.DefaultQuery("lang", config.String("SUMMARY_LANGUAGE", prompt.DefaultLanguage))),
+		Source:   strings.ToLower(c.Query("summary_source")),
+	}
+	switch options.Source {
+	case "", service.SummarySourceAI, service.SummarySourceLocal:
+	default:
+		return options, false
+	}
+	switch options.Mode {
+	case dto.SummaryModeInline, dto.SummaryModeAsync, dto.SummaryModeSkip:
+		return options, true
+	}
+	return options, false
 }
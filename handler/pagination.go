@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/pagination"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePage reads the limit, cursor and include_total query parameters of a list endpoint
+func parsePage(c *gin.Context) pagination.Page {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(pagination.DefaultLimit)))
+	if err != nil || limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+	if limit > pagination.MaxLimit {
+		limit = pagination.MaxLimit
+	}
+	includeTotal, _ := strconv.ParseBool(c.Query("include_total"))
+
+	return pagination.Page{
+		Limit:        limit,
+		Cursor:       c.Query("cursor"),
+		IncludeTotal: includeTotal,
+	}
+}
+
+// paginationBody describes the neighbouring pages of a list response, with ready to follow links
+func paginationBody(c *gin.Context, page pagination.Page, cursors dto.CursorPage) gin.H {
+	body := gin.H{
+		"limit":       page.Limit,
+		"next_cursor": nullableString(cursors.NextCursor),
+		"prev_cursor": nullableString(cursors.PrevCursor),
+		"next":        pageLink(c, cursors.NextCursor),
+		"prev":        pageLink(c, cursors.PrevCursor),
+	}
+	if cursors.Total != nil {
+		body["total"] = *cursors.Total
+	}
+	return body
+}
+
+// pageLink returns the current request URL pointing at cursor, or nil when there is no such page
+func pageLink(c *gin.Context, cursor string) interface{} {
+	if cursor == "" {
+		return nil
+	}
+	link := *c.Request.URL
+	query := link.Query()
+	query.Set("cursor", cursor)
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
+
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/service"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxPriceSubscriptions bounds how many coin IDs and mints one connection may subscribe to
+	maxPriceSubscriptions = 50
+	priceWriteWait        = 10 * time.Second
+	pricePongWait         = 60 * time.Second
+	pricePingPeriod       = pricePongWait * 9 / 10
+)
+
+// PriceHandler handles live price subscriptions
+type PriceHandler struct {
+	priceHub service.PriceSubscriptionHub
+	upgrader websocket.Upgrader
+}
+
+// NewPriceHandler creates a new instance of PriceHandler accepting WebSocket connections from
+// allowedOrigins ("*" allows any). Without allowed origins only same-origin browsers and clients
+// that send no Origin header can connect.
+func NewPriceHandler(priceHub service.PriceSubscriptionHub, allowedOrigins []string) *PriceHandler {
+	return &PriceHandler{
+		priceHub: priceHub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			Subprotocols:    []string{service.WebSocketAuthProtocol},
+			CheckOrigin:     checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// checkOrigin allows requests without an Origin header, same-origin requests and the allowed origins
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.TrimSuffix(strings.ToLower(origin), "/")] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowed["*"] || allowed[strings.ToLower(origin)] {
+			return true
+		}
+		parsed, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(parsed.Host, r.Host)
+	}
+}
+
+// StreamPrices streams price ticks for subscribed coins and mints over a WebSocket
+// StreamPrices godoc
+// @Summary Stream prices over WebSocket
+// @Description Upgrade to a WebSocket and send {"action":"subscribe"|"unsubscribe","coin_ids":[...],"mints":[...]}
+// @Description to receive "tick" messages with price, volume and liquidity of the subscribed CoinGecko coin IDs and Solana mints.
+// @Description Browsers that cannot set the Authorization header pass the access token as the access_token query parameter
+// @Description or as the subprotocols ["bearer", "<token>"].
+// @Tags blockchain
+// @Success 101
+// @Param access_token query string false "Access token, when the Authorization header cannot be set"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/v1/prices/ws [get]
+func (h *PriceHandler) StreamPrices(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	subscriber := h.priceHub.Register()
+	defer subscriber.Close()
+
+	outgoing := make(chan dto.PriceStreamMessage, 8)
+	done := make(chan struct{})
+	defer close(done)
+
+	go writePriceMessages(conn, subscriber, outgoing, done)
+
+	conn.SetReadLimit(64 * 1024)
+	conn.SetReadDeadline(time.Now().Add(pricePongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pricePongWait))
+	})
+
+	for {
+		var message dto.PriceSubscriptionMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			return
+		}
+
+		reply := handlePriceSubscription(subscriber, message)
+		select {
+		case outgoing <- reply:
+		case <-done:
+			return
+		}
+	}
+}
+
+// handlePriceSubscription applies a client message to the subscriber and returns the reply to send
+func handlePriceSubscription(subscriber *service.PriceSubscriber, message dto.PriceSubscriptionMessage) dto.PriceStreamMessage {
+	for _, mint := range message.Mints {
+		if _, err := solana.PublicKeyFromBase58(mint); err != nil {
+			return dto.PriceStreamMessage{Type: "error", Error: "Invalid Solana mint: " + mint}
+		}
+	}
+
+	switch message.Action {
+	case "subscribe":
+		coinIDs, mints := subscriber.Subscriptions()
+		if len(coinIDs)+len(mints)+len(message.CoinIDs)+len(message.Mints) > maxPriceSubscriptions {
+			return dto.PriceStreamMessage{Type: "error", Error: "Too many subscriptions"}
+		}
+		subscriber.Subscribe(message.CoinIDs, message.Mints)
+	case "unsubscribe":
+		subscriber.Unsubscribe(message.CoinIDs, message.Mints)
+	default:
+		return dto.PriceStreamMessage{Type: "error", Error: "Unknown action, use subscribe or unsubscribe"}
+	}
+
+	coinIDs, mints := subscriber.Subscriptions()
+	return dto.PriceStreamMessage{Type: "subscriptions", CoinIDs: coinIDs, Mints: mints}
+}
+
+// writePriceMessages is the only writer of conn: it sends replies, ticks and keepalive pings until done
+func writePriceMessages(conn *websocket.Conn, subscriber *service.PriceSubscriber, outgoing <-chan dto.PriceStreamMessage, done <-chan struct{}) {
+	ping := time.NewTicker(pricePingPeriod)
+	defer ping.Stop()
+
+	write := func(message dto.PriceStreamMessage) bool {
+		conn.SetWriteDeadline(time.Now().Add(priceWriteWait))
+		return conn.WriteJSON(message) == nil
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case message := <-outgoing:
+			if !write(message) {
+				conn.Close()
+				return
+			}
+		case ticks, ok := <-subscriber.Ticks():
+			if !ok {
+				return
+			}
+			if !write(dto.PriceStreamMessage{Type: "tick", Data: ticks}) {
+				conn.Close()
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(priceWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
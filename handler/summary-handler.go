@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"blockchain-scrap/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SummaryHandler handles summary analyses generated in the background
+type SummaryHandler struct {
+	summarySvc service.SummaryService
+}
+
+// NewSummaryHandler creates a new instance of SummaryHandler
+func NewSummaryHandler(summarySvc service.SummaryService) *SummaryHandler {
+	return &SummaryHandler{summarySvc: summarySvc}
+}
+
+// GetSummary gets a summary requested with summary=async
+// GetSummary godoc
+// @Summary Get summary analysis
+// @Description Get a summary analysis generated in the background for a lookup made with summary=async. The status stays pending until it is done.
+// @Tags blockchain
+// @Accept json
+// @Produce json
+// @Param summary-id path string true "Summary ID (UUID)"
+// @Success 200 {object} dto.SummaryJob
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/summaries/{summary-id} [get]
+func (h *SummaryHandler) GetSummary(c *gin.Context) {
+	summaryID, err := uuid.Parse(c.Param("summary-id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID must be a UUID"})
+		return
+	}
+
+	result, errService := h.summarySvc.FindJob(c.Request.Context(), summaryID)
+	if errService != nil {
+		c.JSON(errService.StatusCode(), gin.H{"error": errService.Message()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
 	"blockchain-scrap/service"
 	"net/http"
 
@@ -34,13 +35,14 @@ func (h *SwapHandler) Swap(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.Service.GetSwapTransaction(req)
+	userData := c.MustGet("userData").(*entity.User)
+	result, err := h.Service.GetSwapTransaction(c.Request.Context(), userData.ID, req)
 	if err != nil {
 		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SwapResponse{Transaction: transaction})
+	c.JSON(http.StatusOK, result)
 }
 
 // Swap godoc
@@ -79,6 +81,8 @@ func (h *SwapHandler) GetCurrencySwap(c *gin.Context) {
 // @Param request body dto.SubmitRequest true "Submit Request"
 // @Success 200 {object} dto.SubmitResponse
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/swaps/submit [post]
 func (h *SwapHandler) Submit(c *gin.Context) {
@@ -88,11 +92,64 @@ func (h *SwapHandler) Submit(c *gin.Context) {
 		return
 	}
 
-	signature, err := h.Service.SubmitTransaction(req)
+	userData := c.MustGet("userData").(*entity.User)
+	result, err := h.Service.SubmitTransaction(c.Request.Context(), userData.ID, req)
+	if err != nil {
+		body := gin.H{"error": err.Message()}
+		if result != nil && result.Simulation != nil {
+			body["simulation"] = result.Simulation
+		}
+		c.JSON(err.StatusCode(), body)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSwapStatus godoc
+// @Summary Get swap status
+// @Description Get a submitted swap and its confirmation status by transaction signature
+// @Tags swap
+// @Produce json
+// @Param signature path string true "Transaction signature"
+// @Success 200 {object} dto.SwapStatusResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/swaps/{signature} [get]
+func (h *SwapHandler) GetSwapStatus(c *gin.Context) {
+	userData := c.MustGet("userData").(*entity.User)
+	result, err := h.Service.GetSwapStatus(c.Request.Context(), userData.ID, c.Param("signature"))
+	if err != nil {
+		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSwapHistory godoc
+// @Summary Get swap history
+// @Description Get the swaps of the authenticated user, newest first
+// @Tags swap
+// @Produce json
+// @Param limit query int false "Number of items per page (default: 10, max: 100)"
+// @Param cursor query string false "Cursor of the page to read"
+// @Param include_total query bool false "Count the swaps"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/swaps [get]
+func (h *SwapHandler) GetSwapHistory(c *gin.Context) {
+	userData := c.MustGet("userData").(*entity.User)
+	page := parsePage(c)
+	result, err := h.Service.FindSwapsByUserID(c.Request.Context(), userData.ID, page)
 	if err != nil {
 		c.JSON(err.StatusCode(), gin.H{"error": err.Message()})
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SubmitResponse{Signature: signature})
+	c.JSON(http.StatusOK, gin.H{
+		"data":       result.Swaps,
+		"pagination": paginationBody(c, page, result.CursorPage),
+	})
 }
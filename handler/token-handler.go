@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"blockchain-scrap/dto"
 	"blockchain-scrap/service"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,34 +21,36 @@ func NewTokenHandler(s service.TokenService) *TokenHandler {
 
 // GetAllTokens godoc
 // @Summary Get all tokens
-// @Description Get paginated list of tokens with optional search
+// @Description Get paginated list of tokens with optional search, filters and sorting.
+// @Description Searches are ranked with exact symbol matches first unless sort_by is given.
 // @Tags token
 // @Accept json
 // @Produce json
-// @Param limit query int false "Number of items per page (default: 10)"
-// @Param page query int false "Page number (default: 1)"
-// @Param search query string false "Search term"
+// @Param limit query int false "Number of items per page (default: 10, max: 100)"
+// @Param cursor query string false "Cursor of the page to read, from pagination.next_cursor or pagination.prev_cursor"
+// @Param include_total query bool false "Count the matching tokens"
+// @Param search query string false "Search term matched against name and symbol"
+// @Param address query string false "Exact mint address"
+// @Param tags query string false "Comma separated tags the token must all carry"
+// @Param has_freeze_authority query bool false "Filter on freeze authority presence"
+// @Param has_mint_authority query bool false "Filter on mint authority presence"
+// @Param min_daily_volume query number false "Minimum daily volume"
+// @Param sort_by query string false "relevance (requires search), volume, name, minted_at or id"
+// @Param sort_order query string false "asc or desc (default: asc)"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/v1/tokens [get]
 func (h *TokenHandler) GetAllTokens(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	pageStr := c.DefaultQuery("page", "1")
-	search := c.Query("search")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 10
-	}
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page <= 0 {
-		page = 1
+	filter, errFilter := parseTokenFilter(c)
+	if errFilter != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errFilter.Error()})
+		return
 	}
+	page := parsePage(c)
 
-	offset := (page - 1) * limit
-
-	tokens, errService := h.service.GetAllTokens(limit, offset, search)
-	if err != nil {
+	tokens, errService := h.service.GetAllTokens(filter, page)
+	if errService != nil {
 
 		c.JSON(errService.StatusCode(), gin.H{
 			"message": errService.Message(),
@@ -56,13 +61,8 @@ func (h *TokenHandler) GetAllTokens(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": tokens,
-		"pagination": gin.H{
-			"total": tokens.Total,
-			"page":  page,
-			"limit": limit,
-			"pages": (tokens.Total + int64(limit) - 1) / int64(limit),
-		},
+		"data":       tokens.Tokens,
+		"pagination": paginationBody(c, page, tokens.CursorPage),
 	})
 }
 
@@ -99,3 +99,86 @@ func (h *TokenHandler) GetAccountInfo(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// GetPortfolio godoc
+// @Summary Get wallet portfolio
+// @Description Get the USD value, 24h change and allocation of every token held by a wallet
+// @Tags token
+// @Accept json
+// @Produce json
+// @Param address query string true "Wallet address"
+// @Success 200 {object} dto.PortfolioResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/tokens/portfolio [get]
+func (h *TokenHandler) GetPortfolio(c *gin.Context) {
+	address := c.Query("address")
+
+	if address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Address is required",
+		})
+		return
+	}
+
+	result, errService := h.service.GetPortfolio(c.Request.Context(), address)
+	if errService != nil {
+		c.JSON(errService.StatusCode(), gin.H{
+			"error": errService.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseTokenFilter reads the optional token filters from the query string
+func parseTokenFilter(c *gin.Context) (dto.TokenFilter, error) {
+	filter := dto.TokenFilter{
+		Search:    strings.TrimSpace(c.Query("search")),
+		Address:   strings.TrimSpace(c.Query("address")),
+		SortBy:    c.Query("sort_by"),
+		SortOrder: c.DefaultQuery("sort_order", "asc"),
+	}
+
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+
+	switch filter.SortBy {
+	case "", "relevance", "volume", "name", "minted_at", "id":
+	default:
+		return filter, fmt.Errorf("invalid sort_by %q", filter.SortBy)
+	}
+	if filter.SortBy == "relevance" && filter.Search == "" {
+		return filter, fmt.Errorf("sort_by relevance requires search")
+	}
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		return filter, fmt.Errorf("invalid sort_order %q", filter.SortOrder)
+	}
+
+	for key, target := range map[string]**bool{
+		"has_freeze_authority": &filter.HasFreezeAuthority,
+		"has_mint_authority":   &filter.HasMintAuthority,
+	} {
+		if value := c.Query(key); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s %q", key, value)
+			}
+			*target = &parsed
+		}
+	}
+
+	if value := c.Query("min_daily_volume"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid min_daily_volume %q", value)
+		}
+		filter.MinDailyVolume = &parsed
+	}
+
+	return filter, nil
+}
@@ -13,7 +13,45 @@ import (
 )
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&entity.User{}, &entity.BlockchainSearch{}, &entity.Token{})
+	if err := db.AutoMigrate(&entity.User{}, &entity.BlockchainSearch{}, &entity.Token{}, &entity.RefreshToken{}, &entity.RevokedAccessToken{}, &entity.CacheEntry{}, &entity.Swap{}, &entity.TokenSyncRun{}); err != nil {
+		return err
+	}
+	return migrateTokenIndexes(db)
+}
+
+// migrateTokenIndexes creates the indexes behind token search and filtering that GORM tags cannot express.
+// Trigram indexes need the pg_trgm extension, without it searches still work through sequential scans.
+func migrateTokenIndexes(db *gorm.DB) error {
+	statements := []string{
+		"CREATE INDEX IF NOT EXISTS idx_tokens_lower_symbol ON tokens (LOWER(symbol))",
+		"CREATE INDEX IF NOT EXISTS idx_tokens_daily_volume ON tokens (daily_volume DESC)",
+		// minted_at sorting orders by these expressions so tokens without a minted date come last,
+		// they replace the plain minted_at index the keyset queries could not use
+		"DROP INDEX IF EXISTS idx_tokens_minted_at",
+		"CREATE INDEX IF NOT EXISTS idx_tokens_minted_at_asc ON tokens ((COALESCE(minted_at, '9999-12-31 00:00:00+00'::timestamptz)), id)",
+		"CREATE INDEX IF NOT EXISTS idx_tokens_minted_at_desc ON tokens ((COALESCE(minted_at, '0001-01-01 00:00:00+00'::timestamptz)) DESC, id)",
+		"CREATE INDEX IF NOT EXISTS idx_tokens_tags ON tokens USING GIN (tags jsonb_path_ops)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("pg_trgm extension unavailable, skipping trigram token indexes: %v", err)
+		return nil
+	}
+	trigramStatements := []string{
+		"CREATE INDEX IF NOT EXISTS idx_tokens_name_trgm ON tokens USING GIN (name gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_tokens_symbol_trgm ON tokens USING GIN (symbol gin_trgm_ops)",
+	}
+	for _, statement := range trigramStatements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 var (
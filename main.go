@@ -3,8 +3,13 @@ package main
 import (
 	"blockchain-scrap/handler"
 	"blockchain-scrap/infra"
+	"blockchain-scrap/pkg/cache"
+	"blockchain-scrap/pkg/config"
+	"blockchain-scrap/pkg/prompt"
+	"blockchain-scrap/pkg/solanarpc"
 	"blockchain-scrap/repository"
 	"blockchain-scrap/service"
+	"context"
 	"log"
 	"os"
 	"time"
@@ -22,6 +27,11 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "sync-tokens" {
+		runSyncTokens(db, os.Args[2:])
+		return
+	}
+
 	// err := db.AutoMigrate(&entity.Token{})
 	// if err != nil {
 	// 	log.Fatal("Migration failed:", err)
@@ -35,7 +45,7 @@ func main() {
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Cache", "X-Cache-Age"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -51,21 +61,75 @@ func main() {
 	blockchainSearchRepo := repository.NewBlockchainSearchRepository(db)
 	tokenRepo := repository.NewTokenRepository(db)
 	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	swapRepo := repository.NewSwapRepository(db)
 
 	// Initialize services
-	blockchainService := service.NewBlockchainService(blockchainSearchRepo, tokenRepo)
-	tokenService := service.NewTokenService(tokenRepo)
-	userService := service.NewUserService(userRepo)
-	swapService := service.NewSwapService(tokenRepo, tokenService)
+	// Summary jobs get their own store so market data lookups cannot evict them before they finish
+	cacheStore := cache.NewMemoryStore(config.Int("CACHE_CAPACITY", 1024))
+	summaryJobStore := cache.NewMemoryStore(config.Int("SUMMARY_JOB_CAPACITY", 4096))
+	if os.Getenv("CACHE_BACKEND") == "postgres" {
+		cacheStore = cache.NewTieredStore(cacheStore, repository.NewCacheRepository(db))
+		summaryJobStore = repository.NewCacheRepository(db)
+	}
+	marketDataProvider := service.NewCachedMarketDataProvider(
+		service.NewMarketDataProvider(
+			service.NewCoinGeckoProvider(os.Getenv("COINGECKO_BASE_URL")),
+			service.NewDexScreenerProvider(os.Getenv("DEXSCREENER_BASE_URL")),
+		),
+		cache.New(cacheStore, config.Duration("CACHE_LOAD_TIMEOUT", 30*time.Second)),
+		service.MarketDataCacheTTLsFromEnv(),
+	)
+	cluster, err := solanarpc.ClusterFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure Solana cluster: %v", err)
+	}
+	solanaClient := solanarpc.NewClient(cluster, config.Duration("SOLANA_RPC_TIMEOUT", 30*time.Second))
+	tokenAnalyzer := service.NewSolanaTokenAnalyzer(solanaClient)
+	summaryConfig := service.SummaryConfigFromEnv()
+	prompts, err := prompt.Load(summaryConfig.PromptDir)
+	if err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
+	}
+	summarizer, err := service.NewSummaryGenerator(summaryConfig, prompts)
+	if err != nil {
+		log.Fatalf("Failed to configure summary backend: %v", err)
+	}
+	summaryService := service.NewSummaryService(
+		summarizer,
+		service.NewLocalSummaryGenerator(prompts),
+		summaryConfig.DefaultSource(),
+		summaryJobStore,
+		service.SummaryServiceConfigFromEnv(),
+	)
+	blockchainService := service.NewBlockchainService(blockchainSearchRepo, tokenRepo, marketDataProvider, tokenAnalyzer, summaryService)
+	tokenService := service.NewTokenService(tokenRepo, solanaClient, marketDataProvider, service.NewJupiterPriceProvider(os.Getenv("JUPITER_PRICE_BASE_URL")))
+	userService := service.NewUserService(userRepo, sessionRepo)
+	swapService := service.NewSwapService(tokenRepo, swapRepo, tokenService, solanaClient)
+
+	// Start the shared market stream poller
+	marketHub := service.NewMarketStreamHub(blockchainService, config.Duration("MARKET_STREAM_INTERVAL", 7*time.Second), 100)
+	go marketHub.Run(context.Background())
+	priceHub := service.NewPriceSubscriptionHub(blockchainService, config.Duration("PRICE_TICK_INTERVAL", 10*time.Second))
+	go priceHub.Run(context.Background())
+	if interval := config.Duration("TOKEN_SYNC_INTERVAL", 0); interval > 0 {
+		tokenSync := service.NewTokenSyncService(tokenRepo, repository.NewTokenSyncRepository(db))
+		go tokenSync.RunSchedule(context.Background(), config.String("TOKEN_SYNC_SOURCE", service.TokenListStrict), interval)
+	}
+	swapConfirmer := service.NewSwapConfirmer(swapRepo, solanaClient, config.Duration("SWAP_CONFIRM_INTERVAL", 2*time.Second))
+	go swapConfirmer.Run(context.Background())
 
 	// Initialize handlers
-	blockchainHandler := handler.NewBlockchainHandler(blockchainService)
+	blockchainHandler := handler.NewBlockchainHandler(blockchainService, marketHub)
 	tokenHandler := handler.NewTokenHandler(tokenService)
 	userHandler := handler.NewUserHandler(userService)
 	swapHandler := handler.NewSwapHandler(swapService)
+	priceHandler := handler.NewPriceHandler(priceHub, config.List("WS_ALLOWED_ORIGINS"))
+	summaryHandler := handler.NewSummaryHandler(summaryService)
 
 	//Default first api
 	router.GET("/coins/v2/:contract-address", blockchainHandler.GetBlockchainDetailByContractAddress)
+	router.GET("/coins/v2/:contract-address/stream", blockchainHandler.StreamBlockchainDetailByContractAddress)
 	router.GET("/coins/:blockchain-id/:contract-address", blockchainHandler.GetBlockchainDetailByIDAndContractAddress)
 
 	// API v1 routes
@@ -78,22 +142,31 @@ func main() {
 		{
 			auth.POST("/register", userHandler.Register)
 			auth.POST("/login", userHandler.Login)
+			auth.POST("/refresh", userHandler.Refresh)
+			auth.POST("/logout", userService.Authentication(), userHandler.Logout)
 		}
 
+		// Summary routes, public like /coins/v2 whose async summaries they serve
+		v1.GET("/summaries/:summary-id", summaryHandler.GetSummary)
+
 		// Protected routes
 		protected := v1.Group("")
-		//protected.Use(userService.Authentication())
+		protected.Use(userService.Authentication())
 		{
 			// Token routes
 			tokens := protected.Group("/tokens")
 			{
 				tokens.GET("", tokenHandler.GetAllTokens)
 				tokens.GET("/accounts/", tokenHandler.GetAccountInfo)
+				tokens.GET("/portfolio", tokenHandler.GetPortfolio)
+				tokens.GET("/:address/risk", blockchainHandler.GetTokenRisk)
 			}
 
 			// Swap routes
 			swaps := protected.Group("/swaps")
 			{
+				swaps.GET("", swapHandler.GetSwapHistory)
+				swaps.GET("/:signature", swapHandler.GetSwapStatus)
 				swaps.POST("", swapHandler.Swap)
 				swaps.POST("/submit", swapHandler.Submit)
 				swaps.POST("/quote", swapHandler.GetCurrencySwap)
@@ -105,6 +178,13 @@ func main() {
 				blockchains.GET("", blockchainHandler.GetAllBlockchains)
 				blockchains.GET("/stream", blockchainHandler.StreamBlockchains)
 				blockchains.GET("/:contract-address", blockchainHandler.GetBlockchainDetailByContractAddress)
+				blockchains.GET("/:contract-address/stream", blockchainHandler.StreamBlockchainDetailByContractAddress)
+			}
+
+			// Live price routes
+			prices := protected.Group("/prices")
+			{
+				prices.GET("/ws", priceHandler.StreamPrices)
 			}
 
 			// Search history routes
@@ -112,6 +192,7 @@ func main() {
 			{
 				searches.GET("", blockchainHandler.GetAllBlockchainSearchesByUserID)
 				searches.GET("/:search-id", blockchainHandler.GetBlockchainSearchByID)
+				searches.GET("/contracts/:contract-address", blockchainHandler.GetBlockchainSearchSnapshotsByContract)
 			}
 		}
 	}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status describes how a cached lookup was served
+type Status string
+
+const (
+	StatusHit    Status = "HIT"
+	StatusMiss   Status = "MISS"
+	StatusShared Status = "SHARED" // served by a concurrent identical load
+)
+
+// Loader produces the value for a key on a cache miss. Its context is not cancelled with the
+// caller's, only by the load timeout of the Cache.
+type Loader func(ctx context.Context) ([]byte, error)
+
+// call is an in-flight load shared by concurrent callers of the same key
+type call struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+// Cache puts a Store in front of loaders, deduplicating concurrent loads of the same key
+type Cache struct {
+	store       Store
+	loadTimeout time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]*call
+}
+
+// New creates a Cache backed by store whose loads are given at most loadTimeout
+func New(store Store, loadTimeout time.Duration) *Cache {
+	return &Cache{store: store, loadTimeout: loadTimeout, inflight: make(map[string]*call)}
+}
+
+// GetOrLoad returns the cached value of key, or runs load once for all concurrent callers and caches
+// its result for ttl. Errors are never cached. The outcome is recorded on the Tracker of ctx, if any.
+//
+// The load runs in the background so a caller giving up, the first one included, neither cancels
+// it for the others nor leaves them waiting.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load Loader) ([]byte, error) {
+	if entry, ok := c.store.Get(ctx, key); ok {
+		TrackerFrom(ctx).record(key, StatusHit, entry.StoredAt)
+		return entry.Value, nil
+	}
+
+	status := StatusShared
+	c.mu.Lock()
+	current, ok := c.inflight[key]
+	if !ok {
+		status = StatusMiss
+		current = &call{done: make(chan struct{})}
+		c.inflight[key] = current
+		go c.run(context.WithoutCancel(ctx), key, ttl, current, load)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-current.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	TrackerFrom(ctx).record(key, status, time.Now())
+	return current.value, current.err
+}
+
+// run performs an in-flight load and releases its waiters however the load ends
+func (c *Cache) run(ctx context.Context, key string, ttl time.Duration, current *call, load Loader) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			current.value, current.err = nil, fmt.Errorf("cache load of %s panicked: %v", key, recovered)
+		}
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		close(current.done)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.loadTimeout)
+	defer cancel()
+
+	current.value, current.err = load(ctx)
+	if current.err == nil {
+		c.store.Set(ctx, key, current.value, ttl)
+	}
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry is a cached value together with the time it was stored and the time it expires
+type Entry struct {
+	Value     []byte
+	StoredAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Store defines the contract for cache backends
+type Store interface {
+	Get(ctx context.Context, key string) (*Entry, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+}
+
+// memoryItem is a single element of the LRU list
+type memoryItem struct {
+	key   string
+	entry Entry
+}
+
+// memoryStore is an in-memory LRU Store whose entries also expire after their TTL
+type memoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// NewMemoryStore creates an in-memory LRU Store holding at most capacity entries
+func NewMemoryStore(capacity int) Store {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+	return &memoryStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns an unexpired entry and marks it as most recently used
+func (s *memoryStore) Get(ctx context.Context, key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := element.Value.(*memoryItem)
+	if time.Now().After(item.entry.ExpiresAt) {
+		s.order.Remove(element)
+		delete(s.items, key)
+		return nil, false
+	}
+
+	s.order.MoveToFront(element)
+	entry := item.entry
+	return &entry, true
+}
+
+// Set stores a value, evicting the least recently used entry when the store is full
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	s.setEntry(key, Entry{Value: value, StoredAt: now, ExpiresAt: now.Add(ttl)})
+}
+
+// setEntry stores an entry as is, keeping the stored and expiry times of an entry read elsewhere
+func (s *memoryStore) setEntry(key string, entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := &memoryItem{key: key, entry: entry}
+
+	if element, ok := s.items[key]; ok {
+		element.Value = item
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.items[key] = s.order.PushFront(item)
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryItem).key)
+	}
+}
+
+// entrySetter is implemented by stores that can keep the times of an entry copied from another store
+type entrySetter interface {
+	setEntry(key string, entry Entry)
+}
+
+// tieredStore reads through a fast primary Store backed by a shared secondary Store
+type tieredStore struct {
+	primary   Store
+	secondary Store
+}
+
+// NewTieredStore creates a Store that checks primary first and fills it from secondary on a miss
+func NewTieredStore(primary, secondary Store) Store {
+	return &tieredStore{primary: primary, secondary: secondary}
+}
+
+// Get returns the entry from primary, falling back to secondary and copying its hits into
+// primary for the rest of their TTL
+func (s *tieredStore) Get(ctx context.Context, key string) (*Entry, bool) {
+	if entry, ok := s.primary.Get(ctx, key); ok {
+		return entry, true
+	}
+
+	entry, ok := s.secondary.Get(ctx, key)
+	if !ok {
+		return nil, false
+	}
+	if ttl := time.Until(entry.ExpiresAt); ttl > 0 {
+		if primary, ok := s.primary.(entrySetter); ok {
+			primary.setEntry(key, *entry)
+		} else {
+			s.primary.Set(ctx, key, entry.Value, ttl)
+		}
+	}
+	return entry, true
+}
+
+// Set writes the value to both stores
+func (s *tieredStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	s.primary.Set(ctx, key, value, ttl)
+	s.secondary.Set(ctx, key, value, ttl)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type trackerKey struct{}
+
+// Tracker collects the cache outcome of every lookup made while serving one request
+type Tracker struct {
+	mu       sync.Mutex
+	hits     int
+	misses   int
+	oldestAt time.Time
+}
+
+// WithTracker returns a context that records cache outcomes into a new Tracker
+func WithTracker(ctx context.Context) (context.Context, *Tracker) {
+	tracker := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, tracker), tracker
+}
+
+// TrackerFrom returns the Tracker of ctx, or nil when the request is not tracked
+func TrackerFrom(ctx context.Context) *Tracker {
+	tracker, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return tracker
+}
+
+// record adds the outcome of a lookup; it is a no-op on a nil Tracker
+func (t *Tracker) record(key string, status Status, storedAt time.Time) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if status == StatusHit {
+		t.hits++
+	} else {
+		t.misses++
+	}
+	if t.oldestAt.IsZero() || storedAt.Before(t.oldestAt) {
+		t.oldestAt = storedAt
+	}
+}
+
+// Summary returns HIT when every lookup was cached, MISS when none was, and PARTIAL otherwise,
+// together with the age of the oldest value served. It returns an empty status when nothing was looked up.
+func (t *Tracker) Summary() (string, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case t.hits == 0 && t.misses == 0:
+		return "", 0
+	case t.misses == 0:
+		return string(StatusHit), time.Since(t.oldestAt)
+	case t.hits == 0:
+		return string(StatusMiss), 0
+	default:
+		return "PARTIAL", time.Since(t.oldestAt)
+	}
+}
@@ -91,3 +91,11 @@ func NewRequestTimeout(message string) MessageErr {
 		ErrError:      "Request Timeout",
 	}
 }
+
+func NewConflict(message string) MessageErr {
+	return &MessageErrData{
+		ErrMessage:    message,
+		ErrStatusCode: http.StatusConflict,
+		ErrError:      "CONFLICT",
+	}
+}
@@ -3,6 +3,7 @@ package httprequest
 import (
 	"blockchain-scrap/pkg/errs"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -27,18 +28,23 @@ import (
 // }
 
 func ProcessJSONRequest(method, url string, payload []byte, headers map[string]string) ([]byte, errs.MessageErr) {
+	return ProcessJSONRequestWithContext(context.Background(), method, url, payload, headers)
+}
+
+// ProcessJSONRequestWithContext is ProcessJSONRequest bound to ctx, so the request is aborted when ctx is done
+func ProcessJSONRequestWithContext(ctx context.Context, method, url string, payload []byte, headers map[string]string) ([]byte, errs.MessageErr) {
 	var req *http.Request
 	var err error
 
 	switch method {
 	case "POST":
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(payload))
+		req, err = http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
 		if err != nil {
 			return nil, errs.NewInternalServerError("failed to create POST request: " + err.Error())
 		}
 
 	case "GET":
-		req, err = http.NewRequest(method, url, nil)
+		req, err = http.NewRequestWithContext(ctx, method, url, nil)
 		if err != nil {
 			return nil, errs.NewInternalServerError("failed to create GET request: " + err.Error())
 		}
@@ -69,3 +75,31 @@ func ProcessJSONRequest(method, url string, payload []byte, headers map[string]s
 
 	return body, nil
 }
+
+// ProcessStreamRequest sends a JSON request and returns the response body unread, for streamed
+// responses. The caller must close it.
+func ProcessStreamRequest(ctx context.Context, method, url string, payload []byte, headers map[string]string) (io.ReadCloser, errs.MessageErr) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, errs.NewInternalServerError("failed to create " + method + " request: " + err.Error())
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errs.NewInternalServerError("request failed: " + err.Error())
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return nil, errs.NewInternalServerError(fmt.Sprintf("unexpected status code %d: %s", res.StatusCode, string(body)))
+	}
+
+	return res.Body, nil
+}
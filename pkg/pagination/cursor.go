@@ -0,0 +1,199 @@
+// Package pagination implements opaque cursors for keyset pagination.
+//
+// A list is ordered by a fixed set of keys ending in a unique column. A cursor holds the key
+// values of the row a page starts after (or, when paging backward, before), so the next page is
+// read with a WHERE condition on those values instead of an OFFSET that scans every skipped row.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// DefaultLimit and MaxLimit bound the page size of list endpoints
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or belong to another ordering
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Kind is the type of a key value, used to restore values decoded from JSON
+type Kind int
+
+const (
+	KindNumber Kind = iota
+	KindString
+	KindTime
+)
+
+// Key is one ordering key of a list. SQL may be any expression, with Vars as its placeholders.
+type Key struct {
+	SQL  string
+	Vars []interface{}
+	Desc bool
+	Kind Kind
+}
+
+// Cursor is the position of a page boundary within an ordering
+type Cursor struct {
+	Order    string        `json:"o"`
+	Values   []interface{} `json:"v"`
+	Backward bool          `json:"b,omitempty"`
+}
+
+// Page is a request for one page of a list, Cursor is empty for the first page
+type Page struct {
+	Limit        int
+	Cursor       string
+	IncludeTotal bool
+}
+
+// Encode serializes a cursor into an opaque URL-safe string
+func Encode(cursor Cursor) string {
+	body, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+// Decode parses a cursor produced by Encode for the ordering named order
+func Decode(value, order string, keys []Key) (*Cursor, error) {
+	body, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(body, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Order != order || len(cursor.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, key := range keys {
+		restored, err := restore(cursor.Values[i], key.Kind)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.Values[i] = restored
+	}
+	return &cursor, nil
+}
+
+// restore converts a JSON decoded value back to the Go type of its key
+func restore(value interface{}, kind Kind) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch kind {
+	case KindNumber:
+		if _, ok := value.(float64); !ok {
+			return nil, fmt.Errorf("expected number")
+		}
+		return value, nil
+	case KindTime:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected time")
+		}
+		return time.Parse(time.RFC3339Nano, text)
+	default:
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("expected string")
+		}
+		return value, nil
+	}
+}
+
+// Where returns the condition selecting the rows after the cursor, or before it when paging backward.
+// Keys must not evaluate to NULL, wrap nullable columns in COALESCE.
+func Where(keys []Key, cursor *Cursor) clause.Expr {
+	var (
+		disjuncts []string
+		vars      []interface{}
+	)
+	for i := range keys {
+		var conjuncts []string
+		for j := 0; j < i; j++ {
+			conjuncts = append(conjuncts, "("+keys[j].SQL+") = ?")
+			vars = append(append(vars, keys[j].Vars...), cursor.Values[j])
+		}
+		operator := ">"
+		if keys[i].Desc != cursor.Backward {
+			operator = "<"
+		}
+		conjuncts = append(conjuncts, "("+keys[i].SQL+") "+operator+" ?")
+		vars = append(append(vars, keys[i].Vars...), cursor.Values[i])
+		disjuncts = append(disjuncts, "("+strings.Join(conjuncts, " AND ")+")")
+	}
+	return clause.Expr{SQL: "(" + strings.Join(disjuncts, " OR ") + ")", Vars: vars}
+}
+
+// OrderBy returns the ORDER BY expression of keys, reversed when paging backward
+func OrderBy(keys []Key, backward bool) clause.OrderBy {
+	var (
+		parts []string
+		vars  []interface{}
+	)
+	for _, key := range keys {
+		direction := "ASC"
+		if key.Desc != backward {
+			direction = "DESC"
+		}
+		parts = append(parts, key.SQL+" "+direction)
+		vars = append(vars, key.Vars...)
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(parts, ", "), Vars: vars}}
+}
+
+// Result is a page of rows with the cursors of the neighbouring pages, empty when there is none
+type Result[T any] struct {
+	Items      []T
+	NextCursor string
+	PrevCursor string
+	Total      *int64
+}
+
+// Paginate trims rows read with limit+1 to one page, restores their order when paging backward and
+// computes the neighbouring cursors. cursor is the decoded cursor of the page, values returns the
+// key values of a row.
+func Paginate[T any](rows []T, limit int, cursor *Cursor, order string, values func(T) []interface{}) Result[T] {
+	backward := cursor != nil && cursor.Backward
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	result := Result[T]{Items: rows}
+	if len(rows) == 0 {
+		return result
+	}
+	first := Cursor{Order: order, Values: values(rows[0]), Backward: true}
+	last := Cursor{Order: order, Values: values(rows[len(rows)-1])}
+
+	if backward {
+		if hasMore {
+			result.PrevCursor = Encode(first)
+		}
+		result.NextCursor = Encode(last)
+	} else {
+		if hasMore {
+			result.NextCursor = Encode(last)
+		}
+		if cursor != nil {
+			result.PrevCursor = Encode(first)
+		}
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package pagination
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+var testKeys = []Key{
+	{SQL: "created_at", Desc: true, Kind: KindTime},
+	{SQL: "COALESCE(volume, ?)", Vars: []interface{}{-1}, Kind: KindNumber},
+	{SQL: "id", Kind: KindString},
+}
+
+func TestEncodeDecode(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor := Cursor{Order: "tokens", Values: []interface{}{createdAt, 12.5, "abc"}, Backward: true}
+
+	decoded, err := Decode(Encode(cursor), "tokens", testKeys)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !decoded.Values[0].(time.Time).Equal(createdAt) {
+		t.Errorf("time value = %v, want %v", decoded.Values[0], createdAt)
+	}
+	if decoded.Values[1] != 12.5 || decoded.Values[2] != "abc" || !decoded.Backward {
+		t.Errorf("Decode = %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "%%%"},
+		{name: "not json", value: Encode(Cursor{})[:2]},
+		{name: "other ordering", value: Encode(Cursor{Order: "swaps", Values: []interface{}{"2025-01-01T00:00:00Z", 1, "a"}})},
+		{name: "missing values", value: Encode(Cursor{Order: "tokens", Values: []interface{}{"2025-01-01T00:00:00Z"}})},
+		{name: "bad time", value: Encode(Cursor{Order: "tokens", Values: []interface{}{"yesterday", 1, "a"}})},
+		{name: "bad number", value: Encode(Cursor{Order: "tokens", Values: []interface{}{"2025-01-01T00:00:00Z", "1", "a"}})},
+		{name: "bad string", value: Encode(Cursor{Order: "tokens", Values: []interface{}{"2025-01-01T00:00:00Z", 1, 2}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.value, "tokens", testKeys); err != ErrInvalidCursor {
+				t.Errorf("Decode error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestWhere(t *testing.T) {
+	values := []interface{}{"t", 5.0, "id-1"}
+	tests := []struct {
+		name     string
+		backward bool
+		wantSQL  string
+	}{
+		{
+			name:    "forward",
+			wantSQL: "(((created_at) < ?) OR ((created_at) = ? AND (COALESCE(volume, ?)) > ?) OR ((created_at) = ? AND (COALESCE(volume, ?)) = ? AND (id) > ?))",
+		},
+		{
+			name:     "backward",
+			backward: true,
+			wantSQL:  "(((created_at) > ?) OR ((created_at) = ? AND (COALESCE(volume, ?)) < ?) OR ((created_at) = ? AND (COALESCE(volume, ?)) = ? AND (id) < ?))",
+		},
+	}
+	wantVars := []interface{}{"t", "t", -1, 5.0, "t", -1, 5.0, "id-1"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := Where(testKeys, &Cursor{Values: values, Backward: tt.backward})
+			if expr.SQL != tt.wantSQL {
+				t.Errorf("SQL = %s\nwant  %s", expr.SQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(expr.Vars, wantVars) {
+				t.Errorf("Vars = %v, want %v", expr.Vars, wantVars)
+			}
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		backward bool
+		want     string
+	}{
+		{backward: false, want: "created_at DESC, COALESCE(volume, ?) ASC, id ASC"},
+		{backward: true, want: "created_at ASC, COALESCE(volume, ?) DESC, id DESC"},
+	}
+	for _, tt := range tests {
+		expr := OrderBy(testKeys, tt.backward).Expression.(clause.Expr)
+		if expr.SQL != tt.want || !reflect.DeepEqual(expr.Vars, []interface{}{-1}) {
+			t.Errorf("OrderBy(backward=%v) = %s %v, want %s [-1]", tt.backward, expr.SQL, expr.Vars, tt.want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	values := func(row int) []interface{} { return []interface{}{row} }
+	cursorOf := func(row int, backward bool) string {
+		return Encode(Cursor{Order: "rows", Values: []interface{}{row}, Backward: backward})
+	}
+
+	tests := []struct {
+		name     string
+		rows     []int
+		cursor   *Cursor
+		wantRows []int
+		wantNext string
+		wantPrev string
+	}{
+		{name: "only page", rows: []int{1, 2}, wantRows: []int{1, 2}},
+		{name: "first page", rows: []int{1, 2, 3}, wantRows: []int{1, 2}, wantNext: cursorOf(2, false)},
+		{name: "middle page", rows: []int{3, 4, 5}, cursor: &Cursor{}, wantRows: []int{3, 4}, wantNext: cursorOf(4, false), wantPrev: cursorOf(3, true)},
+		{name: "last page", rows: []int{5}, cursor: &Cursor{}, wantRows: []int{5}, wantPrev: cursorOf(5, true)},
+		{name: "backward page", rows: []int{4, 3, 2}, cursor: &Cursor{Backward: true}, wantRows: []int{3, 4}, wantNext: cursorOf(4, false), wantPrev: cursorOf(3, true)},
+		{name: "backward to the first page", rows: []int{2, 1}, cursor: &Cursor{Backward: true}, wantRows: []int{1, 2}, wantNext: cursorOf(2, false)},
+		{name: "empty", rows: []int{}, cursor: &Cursor{}, wantRows: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Paginate(tt.rows, 2, tt.cursor, "rows", values)
+			if !reflect.DeepEqual(result.Items, tt.wantRows) {
+				t.Errorf("Items = %v, want %v", result.Items, tt.wantRows)
+			}
+			if result.NextCursor != tt.wantNext || result.PrevCursor != tt.wantPrev {
+				t.Errorf("cursors = %q/%q, want %q/%q", result.NextCursor, result.PrevCursor, tt.wantNext, tt.wantPrev)
+			}
+		})
+	}
+}
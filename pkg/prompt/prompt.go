@@ -0,0 +1,90 @@
+// Package prompt loads the text/template prompts sent to AI backends. Every prompt is a
+// "<name>.<language>.tmpl" file, the built-in templates can be overridden or extended with
+// files from a directory.
+package prompt
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// DefaultLanguage is used when a prompt has no variant in the requested language
+const DefaultLanguage = "en"
+
+const extension = ".tmpl"
+
+// funcs are the helpers available to every template
+var funcs = template.FuncMap{"join": strings.Join}
+
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+// Templates is a set of prompts keyed by name and language
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// Load reads the built-in prompts, overridden by the templates in dir when dir is not empty
+func Load(dir string) (*Templates, error) {
+	t := &Templates{templates: map[string]*template.Template{}}
+
+	embedded, err := fs.Sub(builtin, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.loadFS(embedded); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := t.loadFS(os.DirFS(dir)); err != nil {
+			return nil, fmt.Errorf("load prompts from %s: %w", dir, err)
+		}
+	}
+	return t, nil
+}
+
+func (t *Templates) loadFS(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*"+extension)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(path.Base(file), extension)
+		if !strings.Contains(key, ".") {
+			return fmt.Errorf("prompt %s has no language, expected <name>.<language>%s", file, extension)
+		}
+		parsed, err := template.New(key).Option("missingkey=zero").Funcs(funcs).Parse(strings.TrimSpace(string(content)))
+		if err != nil {
+			return fmt.Errorf("parse prompt %s: %w", file, err)
+		}
+		t.templates[key] = parsed
+	}
+	return nil
+}
+
+// Render executes the prompt name in language with data, falling back to DefaultLanguage
+func (t *Templates) Render(name, language string, data interface{}) (string, error) {
+	tmpl, ok := t.templates[name+"."+strings.ToLower(language)]
+	if !ok {
+		tmpl, ok = t.templates[name+"."+DefaultLanguage]
+	}
+	if !ok {
+		return "", fmt.Errorf("prompt %q not found", name)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("render prompt %q: %w", name, err)
+	}
+	return out.String(), nil
+}
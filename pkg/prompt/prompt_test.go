@@ -0,0 +1,65 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderFallsBackToDefaultLanguage(t *testing.T) {
+	templates, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	english, err := templates.Render("coin_summary", DefaultLanguage, nil)
+	if err != nil {
+		t.Fatalf("Render en: %v", err)
+	}
+	for _, language := range []string{"fr", ""} {
+		text, err := templates.Render("coin_summary", language, nil)
+		if err != nil {
+			t.Fatalf("Render %q: %v", language, err)
+		}
+		if text != english {
+			t.Errorf("Render %q = %q, want the English prompt", language, text)
+		}
+	}
+
+	indonesian, err := templates.Render("coin_summary", "ID", nil)
+	if err != nil {
+		t.Fatalf("Render ID: %v", err)
+	}
+	if indonesian == english {
+		t.Error("Render ID returned the English prompt")
+	}
+}
+
+func TestRenderUnknownPrompt(t *testing.T) {
+	templates, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, err := templates.Render("missing", DefaultLanguage, nil); err == nil {
+		t.Error("expected an error for an unknown prompt")
+	}
+}
+
+func TestLoadOverridesBuiltinPrompts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "coin_summary.en.tmpl"), []byte("Summarize {{.}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	text, err := templates.Render("coin_summary", "fr", "DAI")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if text != "Summarize DAI" {
+		t.Errorf("Render = %q, want %q", text, "Summarize DAI")
+	}
+}
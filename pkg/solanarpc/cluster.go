@@ -0,0 +1,51 @@
+package solanarpc
+
+import (
+	"fmt"
+	"strings"
+
+	"blockchain-scrap/pkg/config"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Cluster is a Solana cluster and the RPC endpoints used to reach it
+type Cluster struct {
+	Name      string
+	Endpoints []string
+}
+
+// clusterDefaults are the public endpoints of each supported cluster
+var clusterDefaults = map[string]string{
+	"mainnet":  rpc.MainNetBeta_RPC,
+	"devnet":   rpc.DevNet_RPC,
+	"testnet":  rpc.TestNet_RPC,
+	"localnet": rpc.LocalNet_RPC,
+}
+
+// clusterAliases maps alternative cluster names onto the supported ones
+var clusterAliases = map[string]string{
+	"mainnet-beta":   "mainnet",
+	"local":          "localnet",
+	"test-validator": "localnet",
+}
+
+// ClusterFromEnv resolves the cluster from SOLANA_CLUSTER (mainnet, devnet, testnet or localnet,
+// defaulting to mainnet) and its endpoints from the comma-separated SOLANA_RPC_ENDPOINTS, falling
+// back to the cluster's public endpoint when none are configured.
+func ClusterFromEnv() (Cluster, error) {
+	name := strings.ToLower(config.String("SOLANA_CLUSTER", "mainnet"))
+	if alias, ok := clusterAliases[name]; ok {
+		name = alias
+	}
+	endpoint, ok := clusterDefaults[name]
+	if !ok {
+		return Cluster{}, fmt.Errorf("unsupported SOLANA_CLUSTER %q", name)
+	}
+
+	endpoints := config.List("SOLANA_RPC_ENDPOINTS")
+	if len(endpoints) == 0 {
+		endpoints = []string{endpoint}
+	}
+	return Cluster{Name: name, Endpoints: endpoints}, nil
+}
@@ -0,0 +1,160 @@
+package solanarpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+const (
+	// maxConsecutiveFailures is the number of failed calls after which an endpoint is marked unhealthy
+	maxConsecutiveFailures = 3
+	// unhealthyCooldown is how long an unhealthy endpoint is skipped before it is tried again
+	unhealthyCooldown = 30 * time.Second
+	// nodeUnhealthyCode is the JSON RPC error code a node returns when it is behind the cluster
+	nodeUnhealthyCode = -32005
+)
+
+// endpoint is one RPC node of a Pool and its health
+type endpoint struct {
+	url    string
+	client jsonrpc.RPCClient
+
+	mu             sync.Mutex
+	failures       int
+	unhealthyUntil time.Time
+}
+
+// Pool is a JSON RPC client that spreads calls over several endpoints of a cluster.
+// Calls go to the first healthy endpoint in configuration order and fail over to the next one
+// when an endpoint is unreachable, rate limited or reports itself unhealthy. Endpoints that fail
+// maxConsecutiveFailures times in a row are skipped for unhealthyCooldown.
+type Pool struct {
+	endpoints []*endpoint
+}
+
+// NewPool creates a Pool over the given endpoints, each request is bounded by timeout
+func NewPool(endpoints []string, timeout time.Duration) *Pool {
+	pool := &Pool{}
+	for _, url := range endpoints {
+		pool.endpoints = append(pool.endpoints, &endpoint{
+			url: url,
+			client: jsonrpc.NewClientWithOpts(url, &jsonrpc.RPCClientOpts{
+				HTTPClient: &http.Client{Timeout: timeout},
+			}),
+		})
+	}
+	return pool
+}
+
+// NewClient creates a Solana RPC client for the cluster whose calls fail over between its endpoints
+func NewClient(cluster Cluster, timeout time.Duration) *rpc.Client {
+	return rpc.NewWithCustomRPCClient(NewPool(cluster.Endpoints, timeout))
+}
+
+// CallForInto implements rpc.JSONRPCClient
+func (p *Pool) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	return p.call(ctx, func(client jsonrpc.RPCClient) error {
+		return client.CallForInto(ctx, out, method, params)
+	})
+}
+
+// CallWithCallback implements rpc.JSONRPCClient
+func (p *Pool) CallWithCallback(ctx context.Context, method string, params []interface{}, callback func(*http.Request, *http.Response) error) error {
+	return p.call(ctx, func(client jsonrpc.RPCClient) error {
+		return client.CallWithCallback(ctx, method, params, callback)
+	})
+}
+
+// CallBatch implements rpc.JSONRPCClient
+func (p *Pool) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	var responses jsonrpc.RPCResponses
+	err := p.call(ctx, func(client jsonrpc.RPCClient) error {
+		var err error
+		responses, err = client.CallBatch(ctx, requests)
+		return err
+	})
+	return responses, err
+}
+
+// call runs fn against each endpoint in order until one of them answers
+func (p *Pool) call(ctx context.Context, fn func(client jsonrpc.RPCClient) error) error {
+	var lastErr error
+	for _, ep := range p.ordered(time.Now()) {
+		err := fn(ep.client)
+		if err == nil || !isEndpointFailure(ctx, err) {
+			ep.recordSuccess()
+			return err
+		}
+		ep.recordFailure(err)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		return errors.New("solanarpc: no RPC endpoints configured")
+	}
+	return lastErr
+}
+
+// ordered returns the healthy endpoints followed by those cooling down, so a call is always attempted
+func (p *Pool) ordered(now time.Time) []*endpoint {
+	healthy := make([]*endpoint, 0, len(p.endpoints))
+	var coolingDown []*endpoint
+	for _, ep := range p.endpoints {
+		if ep.isHealthy(now) {
+			healthy = append(healthy, ep)
+		} else {
+			coolingDown = append(coolingDown, ep)
+		}
+	}
+	return append(healthy, coolingDown...)
+}
+
+// isEndpointFailure reports whether err is caused by the endpoint rather than by the request itself
+func isEndpointFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == nodeUnhealthyCode
+	}
+	// Transport errors, timeouts and non JSON RPC HTTP responses such as 429 or 503
+	return true
+}
+
+func (e *endpoint) isHealthy(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return now.After(e.unhealthyUntil)
+}
+
+func (e *endpoint) recordSuccess() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failures >= maxConsecutiveFailures {
+		log.Printf("solanarpc: endpoint %s recovered", e.url)
+	}
+	e.failures = 0
+	e.unhealthyUntil = time.Time{}
+}
+
+func (e *endpoint) recordFailure(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+	if e.failures >= maxConsecutiveFailures {
+		if e.failures == maxConsecutiveFailures {
+			log.Printf("solanarpc: endpoint %s marked unhealthy: %v", e.url, err)
+		}
+		e.unhealthyUntil = time.Now().Add(unhealthyCooldown)
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"blockchain-scrap/entity"
 	"blockchain-scrap/pkg/errs"
+	"blockchain-scrap/pkg/pagination"
 	"context"
 
 	"github.com/google/uuid"
@@ -13,10 +14,9 @@ import (
 type BlockchainSearchRepository interface {
 	Save(ctx context.Context, record *entity.BlockchainSearch) errs.MessageErr
 	Update(ctx context.Context, record *entity.BlockchainSearch) errs.MessageErr
-	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.BlockchainSearch, errs.MessageErr)
-	FindByUserIDAndContract(ctx context.Context, userID uint, contractAddress string) (*entity.BlockchainSearch, errs.MessageErr)
-	FindByID(ctx context.Context, ID uuid.UUID) (*entity.BlockchainSearch, errs.MessageErr)
-	SaveOrUpdate(ctx context.Context, record *entity.BlockchainSearch) errs.MessageErr
+	FindByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*pagination.Result[*entity.BlockchainSearch], errs.MessageErr)
+	FindByUserIDAndContract(ctx context.Context, userID uuid.UUID, contractAddress string, page pagination.Page) (*pagination.Result[*entity.BlockchainSearch], errs.MessageErr)
+	FindByID(ctx context.Context, userID, ID uuid.UUID) (*entity.BlockchainSearch, errs.MessageErr)
 }
 
 // blockchainSearchRepositoryImpl implements BlockchainSearchRepository
@@ -29,10 +29,10 @@ func NewBlockchainSearchRepository(db *gorm.DB) BlockchainSearchRepository {
 	return &blockchainSearchRepositoryImpl{db: db}
 }
 
-// FindByID searches for search history by ID
-func (r *blockchainSearchRepositoryImpl) FindByID(ctx context.Context, ID uuid.UUID) (*entity.BlockchainSearch, errs.MessageErr) {
+// FindByID searches for a search history entry of a user by ID
+func (r *blockchainSearchRepositoryImpl) FindByID(ctx context.Context, userID, ID uuid.UUID) (*entity.BlockchainSearch, errs.MessageErr) {
 	var record entity.BlockchainSearch
-	err := r.db.WithContext(ctx).Where("id = ?", ID).First(&record).Error
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", ID, userID).First(&record).Error
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -61,48 +61,55 @@ func (r *blockchainSearchRepositoryImpl) Update(ctx context.Context, record *ent
 	return nil
 }
 
-// FindByUserID searches for all search history by user ID
-func (r *blockchainSearchRepositoryImpl) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.BlockchainSearch, errs.MessageErr) {
-	var records []*entity.BlockchainSearch
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&records).Error
-	if err != nil {
-		return nil, errs.NewInternalServerError("Failed to fetch data")
-	}
-	return records, nil
+// searchHistoryOrder is the newest first keyset ordering of search history
+var searchHistoryOrder = []pagination.Key{
+	{SQL: "created_at", Desc: true, Kind: pagination.KindTime},
+	{SQL: "id", Desc: true, Kind: pagination.KindString},
 }
 
-// FindByUserIDAndContract searches for search history by user ID and contract address
-func (r *blockchainSearchRepositoryImpl) FindByUserIDAndContract(ctx context.Context, userID uint, contractAddress string) (*entity.BlockchainSearch, errs.MessageErr) {
-	var record entity.BlockchainSearch
-	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND contract_address = ?", userID, contractAddress).
-		First(&record).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errs.NewNotFound("Data not found")
-		}
-		return nil, errs.NewInternalServerError("Failed to fetch data")
-	}
-	return &record, nil
+// FindByUserID searches for one page of the search history of a user, newest first
+func (r *blockchainSearchRepositoryImpl) FindByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*pagination.Result[*entity.BlockchainSearch], errs.MessageErr) {
+	query := r.db.WithContext(ctx).Model(&entity.BlockchainSearch{}).Where("user_id = ?", userID)
+	return findSearchPage(query, page)
 }
 
-// SaveOrUpdate saves or updates search history
-func (r *blockchainSearchRepositoryImpl) SaveOrUpdate(ctx context.Context, record *entity.BlockchainSearch) errs.MessageErr {
-	var existingRecord entity.BlockchainSearch
+// FindByUserIDAndContract searches for one page of the search snapshots of a contract address made by a user, newest first
+func (r *blockchainSearchRepositoryImpl) FindByUserIDAndContract(ctx context.Context, userID uuid.UUID, contractAddress string, page pagination.Page) (*pagination.Result[*entity.BlockchainSearch], errs.MessageErr) {
+	query := r.db.WithContext(ctx).Model(&entity.BlockchainSearch{}).
+		Where("user_id = ? AND contract_address = ?", userID, contractAddress)
+	return findSearchPage(query, page)
+}
 
-	if err := r.db.Where("contract_address = ? AND user_id = ?", record.ContractAddress, record.UserID).First(&existingRecord).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			if err := r.db.Create(record).Error; err != nil {
-				return errs.NewInternalServerError("Failed to save data")
-			}
-		} else {
-			return errs.NewInternalServerError("Failed to process data")
+// findSearchPage reads one page of the search history selected by query, newest first
+func findSearchPage(query *gorm.DB, page pagination.Page) (*pagination.Result[*entity.BlockchainSearch], errs.MessageErr) {
+	var total *int64
+	if page.IncludeTotal {
+		var count int64
+		if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return nil, errs.NewInternalServerError("Failed to fetch data")
 		}
-	} else {
-		record.ID = existingRecord.ID
-		if err := r.db.Save(record).Error; err != nil {
-			return errs.NewInternalServerError("Failed to update data")
+		total = &count
+	}
+
+	var cursor *pagination.Cursor
+	if page.Cursor != "" {
+		decoded, err := pagination.Decode(page.Cursor, "searches", searchHistoryOrder)
+		if err != nil {
+			return nil, errs.NewBadRequest("Invalid cursor")
 		}
+		cursor = decoded
+		query = query.Where(pagination.Where(searchHistoryOrder, cursor))
 	}
-	return nil
+
+	var records []*entity.BlockchainSearch
+	err := query.Clauses(pagination.OrderBy(searchHistoryOrder, cursor != nil && cursor.Backward)).Limit(page.Limit + 1).Find(&records).Error
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch data")
+	}
+
+	result := pagination.Paginate(records, page.Limit, cursor, "searches", func(record *entity.BlockchainSearch) []interface{} {
+		return []interface{}{record.CreatedAt, record.ID.String()}
+	})
+	result.Total = total
+	return &result, nil
 }
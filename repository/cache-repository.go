@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/cache"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxCacheKeyLength is the size of the cache_entries key column
+const maxCacheKeyLength = 512
+
+// storageKey returns the key a cache entry is stored under. Keys that do not fit the key column
+// are replaced by their SHA-256 digest.
+func storageKey(key string) string {
+	if len(key) <= maxCacheKeyLength {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// cacheRepositoryImpl implements cache.Store on the cache_entries table
+type cacheRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewCacheRepository creates a Postgres-backed cache.Store shared by every API instance
+func NewCacheRepository(db *gorm.DB) cache.Store {
+	return &cacheRepositoryImpl{db: db}
+}
+
+// Get returns an unexpired cache entry; lookup failures are treated as misses
+func (r *cacheRepositoryImpl) Get(ctx context.Context, key string) (*cache.Entry, bool) {
+	var record entity.CacheEntry
+	err := r.db.WithContext(ctx).Where("key = ? AND expires_at > ?", storageKey(key), time.Now()).First(&record).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Println("Failed to read cache entry:", err.Error())
+		}
+		return nil, false
+	}
+	return &cache.Entry{Value: record.Value, StoredAt: record.StoredAt, ExpiresAt: record.ExpiresAt}, true
+}
+
+// Set upserts a cache entry; write failures are logged and otherwise ignored
+func (r *cacheRepositoryImpl) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	record := &entity.CacheEntry{Key: storageKey(key), Value: value, StoredAt: now, ExpiresAt: now.Add(ttl)}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "stored_at", "expires_at"}),
+	}).Create(record).Error
+	if err != nil {
+		log.Println("Failed to write cache entry:", err.Error())
+	}
+}
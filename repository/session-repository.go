@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/errs"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SessionRepository defines the contract for refresh token and access token revocation storage
+type SessionRepository interface {
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) errs.MessageErr
+	FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, errs.MessageErr)
+	RotateRefreshToken(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) errs.MessageErr
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) errs.MessageErr
+	RevokeAccessToken(ctx context.Context, token *entity.RevokedAccessToken) errs.MessageErr
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, errs.MessageErr)
+}
+
+// sessionRepositoryImpl implements SessionRepository
+type sessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepository
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepositoryImpl{db: db}
+}
+
+// CreateRefreshToken saves a newly issued refresh token
+func (r *sessionRepositoryImpl) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) errs.MessageErr {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return errs.NewInternalServerError("Failed to save refresh token")
+	}
+	return nil
+}
+
+// FindRefreshTokenByHash searches for a refresh token by the hash of its value
+func (r *sessionRepositoryImpl) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, errs.MessageErr) {
+	var token entity.RefreshToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errs.NewUnauthenticated("Invalid refresh token")
+		}
+		return nil, errs.NewInternalServerError("Failed to fetch refresh token")
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes the current refresh token and saves its replacement in one transaction
+func (r *sessionRepositoryImpl) RotateRefreshToken(ctx context.Context, current *entity.RefreshToken, next *entity.RefreshToken) errs.MessageErr {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		// Only rotate a token that is still unrevoked, so concurrent refreshes cannot both succeed
+		result := tx.Model(&entity.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by_id": next.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errs.NewUnauthenticated("Refresh token has already been used")
+		}
+		return errs.NewInternalServerError("Failed to rotate refresh token")
+	}
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every active refresh token of a rotation family
+func (r *sessionRepositoryImpl) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) errs.MessageErr {
+	err := r.db.WithContext(ctx).
+		Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return errs.NewInternalServerError("Failed to revoke refresh token")
+	}
+	return nil
+}
+
+// RevokeAccessToken blocks an access token until it expires and prunes revocations that already expired
+func (r *sessionRepositoryImpl) RevokeAccessToken(ctx context.Context, token *entity.RevokedAccessToken) errs.MessageErr {
+	db := r.db.WithContext(ctx)
+	if err := db.Save(token).Error; err != nil {
+		return errs.NewInternalServerError("Failed to revoke access token")
+	}
+	if err := db.Where("expires_at < ?", time.Now()).Delete(&entity.RevokedAccessToken{}).Error; err != nil {
+		return errs.NewInternalServerError("Failed to prune revoked access tokens")
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked checks whether an access token has been revoked
+func (r *sessionRepositoryImpl) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, errs.MessageErr) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.RevokedAccessToken{}).Where("jti = ?", jti).Count(&count).Error
+	if err != nil {
+		return false, errs.NewInternalServerError("Failed to check access token")
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/errs"
+	"blockchain-scrap/pkg/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SwapRepository defines the contract for swap transaction storage
+type SwapRepository interface {
+	Create(ctx context.Context, swap *entity.Swap) errs.MessageErr
+	Save(ctx context.Context, swap *entity.Swap) errs.MessageErr
+	ClaimForSubmission(ctx context.Context, id uuid.UUID, submittedAt time.Time) (bool, errs.MessageErr)
+	ReleaseClaim(ctx context.Context, id uuid.UUID) errs.MessageErr
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.Swap, errs.MessageErr)
+	FindBySignature(ctx context.Context, signature string) (*entity.Swap, errs.MessageErr)
+	FindByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*pagination.Result[*entity.Swap], errs.MessageErr)
+	FindPending(ctx context.Context, limit int) ([]*entity.Swap, errs.MessageErr)
+}
+
+// swapRepositoryImpl implements SwapRepository
+type swapRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewSwapRepository creates a new instance of SwapRepository
+func NewSwapRepository(db *gorm.DB) SwapRepository {
+	return &swapRepositoryImpl{db: db}
+}
+
+// Create saves a new swap record
+func (r *swapRepositoryImpl) Create(ctx context.Context, swap *entity.Swap) errs.MessageErr {
+	if err := r.db.WithContext(ctx).Create(swap).Error; err != nil {
+		return errs.NewInternalServerError("Failed to save swap")
+	}
+	return nil
+}
+
+// Save updates every column of an existing swap record
+func (r *swapRepositoryImpl) Save(ctx context.Context, swap *entity.Swap) errs.MessageErr {
+	if err := r.db.WithContext(ctx).Omit("User").Save(swap).Error; err != nil {
+		return errs.NewInternalServerError("Failed to update swap")
+	}
+	return nil
+}
+
+// ClaimForSubmission atomically moves a created swap to submitted, returning false when the swap
+// was already claimed by another request
+func (r *swapRepositoryImpl) ClaimForSubmission(ctx context.Context, id uuid.UUID, submittedAt time.Time) (bool, errs.MessageErr) {
+	result := r.db.WithContext(ctx).Model(&entity.Swap{}).
+		Where("id = ? AND status = ?", id, entity.SwapStatusCreated).
+		Updates(map[string]interface{}{"status": entity.SwapStatusSubmitted, "submitted_at": submittedAt})
+	if result.Error != nil {
+		return false, errs.NewInternalServerError("Failed to update swap")
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseClaim moves a claimed swap that was never sent back to created
+func (r *swapRepositoryImpl) ReleaseClaim(ctx context.Context, id uuid.UUID) errs.MessageErr {
+	err := r.db.WithContext(ctx).Model(&entity.Swap{}).
+		Where("id = ? AND status = ? AND signature IS NULL", id, entity.SwapStatusSubmitted).
+		Updates(map[string]interface{}{"status": entity.SwapStatusCreated, "submitted_at": nil}).Error
+	if err != nil {
+		return errs.NewInternalServerError("Failed to update swap")
+	}
+	return nil
+}
+
+// FindByID searches for a swap by its ID
+func (r *swapRepositoryImpl) FindByID(ctx context.Context, id uuid.UUID) (*entity.Swap, errs.MessageErr) {
+	var swap entity.Swap
+	if err := r.db.WithContext(ctx).First(&swap, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errs.NewNotFound("Swap not found")
+		}
+		return nil, errs.NewInternalServerError("Failed to fetch swap")
+	}
+	return &swap, nil
+}
+
+// FindBySignature searches for a swap by its transaction signature
+func (r *swapRepositoryImpl) FindBySignature(ctx context.Context, signature string) (*entity.Swap, errs.MessageErr) {
+	var swap entity.Swap
+	if err := r.db.WithContext(ctx).First(&swap, "signature = ?", signature).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errs.NewNotFound("Swap not found")
+		}
+		return nil, errs.NewInternalServerError("Failed to fetch swap")
+	}
+	return &swap, nil
+}
+
+// swapHistoryOrder is the newest first keyset ordering of swap history
+var swapHistoryOrder = []pagination.Key{
+	{SQL: "created_at", Desc: true, Kind: pagination.KindTime},
+	{SQL: "id", Desc: true, Kind: pagination.KindString},
+}
+
+// FindByUserID returns one page of the swaps of a user, newest first
+func (r *swapRepositoryImpl) FindByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*pagination.Result[*entity.Swap], errs.MessageErr) {
+	query := r.db.WithContext(ctx).Model(&entity.Swap{}).Where("user_id = ?", userID)
+
+	var total *int64
+	if page.IncludeTotal {
+		var count int64
+		if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return nil, errs.NewInternalServerError("Failed to fetch swaps")
+		}
+		total = &count
+	}
+
+	var cursor *pagination.Cursor
+	if page.Cursor != "" {
+		decoded, err := pagination.Decode(page.Cursor, "swaps", swapHistoryOrder)
+		if err != nil {
+			return nil, errs.NewBadRequest("Invalid cursor")
+		}
+		cursor = decoded
+		query = query.Where(pagination.Where(swapHistoryOrder, cursor))
+	}
+
+	var swaps []*entity.Swap
+	err := query.Clauses(pagination.OrderBy(swapHistoryOrder, cursor != nil && cursor.Backward)).Limit(page.Limit + 1).Find(&swaps).Error
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch swaps")
+	}
+
+	result := pagination.Paginate(swaps, page.Limit, cursor, "swaps", func(swap *entity.Swap) []interface{} {
+		return []interface{}{swap.CreatedAt, swap.ID.String()}
+	})
+	result.Total = total
+	return &result, nil
+}
+
+// FindPending returns the oldest submitted swaps that have not reached a final status
+func (r *swapRepositoryImpl) FindPending(ctx context.Context, limit int) ([]*entity.Swap, errs.MessageErr) {
+	var swaps []*entity.Swap
+	err := r.db.WithContext(ctx).
+		Where("status IN ? AND signature IS NOT NULL", []string{entity.SwapStatusSubmitted, entity.SwapStatusConfirmed}).
+		Order("submitted_at ASC").
+		Limit(limit).
+		Find(&swaps).Error
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch pending swaps")
+	}
+	return swaps, nil
+}
@@ -1,15 +1,23 @@
 package repository
 
 import (
+	"blockchain-scrap/dto"
 	"blockchain-scrap/entity"
 	"blockchain-scrap/pkg/errs"
+	"blockchain-scrap/pkg/pagination"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TokenRepository interface {
-	GetAll(limit, offset int, search string) ([]*entity.Token, int64, errs.MessageErr)
+	GetAll(filter dto.TokenFilter, page pagination.Page) (*pagination.Result[*entity.Token], errs.MessageErr)
 	FindByAddress(addresses []string) ([]*entity.Token, errs.MessageErr)
+	Upsert(ctx context.Context, tokens []*entity.Token) errs.MessageErr
 }
 
 type tokenRepository struct {
@@ -36,24 +44,204 @@ func (r *tokenRepository) FindByAddress(addresses []string) ([]*entity.Token, er
 	return record, nil
 }
 
-func (r *tokenRepository) GetAll(limit, offset int, search string) ([]*entity.Token, int64, errs.MessageErr) {
+func (r *tokenRepository) GetAll(filter dto.TokenFilter, page pagination.Page) (*pagination.Result[*entity.Token], errs.MessageErr) {
+	query := r.db.Model(&entity.Token{})
+
+	if filter.Address != "" {
+		query = query.Where("address = ?", filter.Address)
+	}
+	if filter.Search != "" {
+		searchTerm := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("name ILIKE ? OR symbol ILIKE ?", searchTerm, searchTerm)
+	}
+	if len(filter.Tags) > 0 {
+		tags, err := json.Marshal(filter.Tags)
+		if err != nil {
+			return nil, errs.NewBadRequest("Invalid tags")
+		}
+		query = query.Where("tags @> ?::jsonb", string(tags))
+	}
+	if filter.HasFreezeAuthority != nil {
+		query = query.Where(nullCondition("freeze_authority", *filter.HasFreezeAuthority))
+	}
+	if filter.HasMintAuthority != nil {
+		query = query.Where(nullCondition("mint_authority", *filter.HasMintAuthority))
+	}
+	if filter.MinDailyVolume != nil {
+		query = query.Where("daily_volume >= ?", *filter.MinDailyVolume)
+	}
+
+	var total *int64
+	if page.IncludeTotal {
+		var count int64
+		if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return nil, errs.NewInternalServerError(err.Error())
+		}
+		total = &count
+	}
+
+	order := newTokenOrder(filter)
+	var cursor *pagination.Cursor
+	backward := false
+	if page.Cursor != "" {
+		decoded, err := pagination.Decode(page.Cursor, order.name, order.keys)
+		if err != nil {
+			return nil, errs.NewBadRequest("Invalid cursor")
+		}
+		cursor = decoded
+		query = query.Where(pagination.Where(order.keys, cursor))
+		backward = cursor.Backward
+	}
+
 	var tokens []*entity.Token
-	var total int64
+	if err := query.Clauses(pagination.OrderBy(order.keys, backward)).Limit(page.Limit + 1).Find(&tokens).Error; err != nil {
+		return nil, errs.NewInternalServerError(err.Error())
+	}
 
-	query := r.db.Model(&entity.Token{})
+	result := pagination.Paginate(tokens, page.Limit, cursor, order.name, order.values)
+	result.Total = total
+	return &result, nil
+}
+
+// Minted date sort keys, tokens without a minted date get a sentinel that sorts them last
+const (
+	mintedAtAscSQL  = "COALESCE(minted_at, '9999-12-31 00:00:00+00'::timestamptz)"
+	mintedAtDescSQL = "COALESCE(minted_at, '0001-01-01 00:00:00+00'::timestamptz)"
+)
 
-	if search != "" {
-		searchTerm := "%" + search + "%"
-		query = query.Where("LOWER(name) LIKE LOWER(?) OR LOWER(symbol) LIKE LOWER(?)", searchTerm, searchTerm)
+// tokenOrder is a keyset ordering of tokens and how to read its key values from a row
+type tokenOrder struct {
+	name   string
+	keys   []pagination.Key
+	values func(token *entity.Token) []interface{}
+}
+
+// newTokenOrder picks the ordering of a token query. Searches default to relevance ranking: exact
+// symbol matches, then exact name matches, then symbol prefix matches, each ordered by daily volume.
+// Every ordering ends with the id so keys are unique.
+func newTokenOrder(filter dto.TokenFilter) tokenOrder {
+	desc := filter.SortOrder == "desc"
+	idKey := pagination.Key{SQL: "id", Kind: pagination.KindNumber}
+
+	switch filter.SortBy {
+	case "volume":
+		return tokenOrder{
+			name: "volume:" + filter.SortOrder,
+			keys: []pagination.Key{{SQL: "daily_volume", Desc: desc, Kind: pagination.KindNumber}, idKey},
+			values: func(token *entity.Token) []interface{} {
+				return []interface{}{token.DailyVolume, float64(token.ID)}
+			},
+		}
+	case "name":
+		return tokenOrder{
+			name: "name:" + filter.SortOrder,
+			keys: []pagination.Key{{SQL: "name", Desc: desc, Kind: pagination.KindString}, idKey},
+			values: func(token *entity.Token) []interface{} {
+				return []interface{}{token.Name, float64(token.ID)}
+			},
+		}
+	case "minted_at":
+		// Tokens without a minted date sort last in both directions. The sentinels are inlined so the
+		// expressions match the idx_tokens_minted_at_asc and idx_tokens_minted_at_desc indexes.
+		missing, mintedAtSQL := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), mintedAtAscSQL
+		if desc {
+			missing, mintedAtSQL = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), mintedAtDescSQL
+		}
+		return tokenOrder{
+			name: "minted_at:" + filter.SortOrder,
+			keys: []pagination.Key{
+				{SQL: mintedAtSQL, Desc: desc, Kind: pagination.KindTime},
+				idKey,
+			},
+			values: func(token *entity.Token) []interface{} {
+				mintedAt := missing
+				if token.MintedAt != nil {
+					mintedAt = *token.MintedAt
+				}
+				return []interface{}{mintedAt, float64(token.ID)}
+			},
+		}
+	case "id":
+		return tokenOrder{
+			name: "id:" + filter.SortOrder,
+			keys: []pagination.Key{{SQL: "id", Desc: desc, Kind: pagination.KindNumber}},
+			values: func(token *entity.Token) []interface{} {
+				return []interface{}{float64(token.ID)}
+			},
+		}
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, errs.NewInternalServerError(err.Error())
+	if filter.Search == "" {
+		return tokenOrder{
+			name: "id:asc",
+			keys: []pagination.Key{idKey},
+			values: func(token *entity.Token) []interface{} {
+				return []interface{}{float64(token.ID)}
+			},
+		}
 	}
 
-	if err := query.Limit(limit).Offset(offset).Order("id asc").Find(&tokens).Error; err != nil {
-		return nil, 0, errs.NewInternalServerError(err.Error())
+	prefix := escapeLike(filter.Search) + "%"
+	return tokenOrder{
+		name: "relevance:" + filter.Search,
+		keys: []pagination.Key{
+			{
+				SQL:  "CASE WHEN LOWER(symbol) = LOWER(?) THEN 0 WHEN LOWER(name) = LOWER(?) THEN 1 WHEN symbol ILIKE ? THEN 2 ELSE 3 END",
+				Vars: []interface{}{filter.Search, filter.Search, prefix},
+				Kind: pagination.KindNumber,
+			},
+			{SQL: "daily_volume", Desc: true, Kind: pagination.KindNumber},
+			idKey,
+		},
+		values: func(token *entity.Token) []interface{} {
+			return []interface{}{searchRank(token, filter.Search), token.DailyVolume, float64(token.ID)}
+		},
 	}
+}
 
-	return tokens, total, nil
+// searchRank mirrors the relevance CASE expression for a loaded token
+func searchRank(token *entity.Token, search string) float64 {
+	switch {
+	case strings.EqualFold(token.Symbol, search):
+		return 0
+	case strings.EqualFold(token.Name, search):
+		return 1
+	case strings.HasPrefix(strings.ToLower(token.Symbol), strings.ToLower(search)):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// nullCondition returns the SQL condition for a column being set or not
+func nullCondition(column string, present bool) string {
+	if present {
+		return column + " IS NOT NULL"
+	}
+	return column + " IS NULL"
+}
+
+// escapeLike escapes the LIKE wildcards of a user supplied search term
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+}
+
+// Upsert inserts tokens, updating every column but created_at of the tokens whose address already exists
+func (r *tokenRepository) Upsert(ctx context.Context, tokens []*entity.Token) errs.MessageErr {
+	if len(tokens) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "address"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"daily_volume", "decimals", "freeze_authority", "logo_uri", "mint_authority",
+				"minted_at", "name", "permanent_delegate", "symbol", "tags", "extensions",
+			}),
+		}).
+		Create(&tokens).Error
+	if err != nil {
+		return errs.NewInternalServerError("Failed to upsert tokens: " + err.Error())
+	}
+	return nil
 }
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/errs"
+
+	"gorm.io/gorm"
+)
+
+// TokenSyncRepository defines the contract for token list sync run storage
+type TokenSyncRepository interface {
+	Create(ctx context.Context, run *entity.TokenSyncRun) errs.MessageErr
+	Save(ctx context.Context, run *entity.TokenSyncRun) errs.MessageErr
+}
+
+// tokenSyncRepositoryImpl implements TokenSyncRepository
+type tokenSyncRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewTokenSyncRepository creates a new instance of TokenSyncRepository
+func NewTokenSyncRepository(db *gorm.DB) TokenSyncRepository {
+	return &tokenSyncRepositoryImpl{db: db}
+}
+
+// Create saves a new sync run
+func (r *tokenSyncRepositoryImpl) Create(ctx context.Context, run *entity.TokenSyncRun) errs.MessageErr {
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		return errs.NewInternalServerError("Failed to save token sync run")
+	}
+	return nil
+}
+
+// Save updates every column of an existing sync run
+func (r *tokenSyncRepositoryImpl) Save(ctx context.Context, run *entity.TokenSyncRun) errs.MessageErr {
+	if err := r.db.WithContext(ctx).Save(run).Error; err != nil {
+		return errs.NewInternalServerError("Failed to update token sync run")
+	}
+	return nil
+}
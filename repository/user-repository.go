@@ -6,6 +6,7 @@ import (
 	"blockchain-scrap/entity"
 	"blockchain-scrap/pkg/errs"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -13,6 +14,7 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) errs.MessageErr
 	FindByEmail(ctx context.Context, email string) (*entity.User, errs.MessageErr)
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.User, errs.MessageErr)
 }
 
 // userRepositoryImpl implements UserRepository
@@ -43,3 +45,13 @@ func (r *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (*en
 	}
 	return &user, nil
 }
+
+// FindByID searches for a user by ID
+func (r *userRepositoryImpl) FindByID(ctx context.Context, id uuid.UUID) (*entity.User, errs.MessageErr) {
+	var user entity.User
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
+	if err != nil {
+		return nil, errs.NewNotFound("user not found")
+	}
+	return &user, nil
+}
@@ -2,12 +2,13 @@ package service
 
 import (
 	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
 	"blockchain-scrap/pkg/errs"
-	httprequest "blockchain-scrap/pkg/http-request"
+	"blockchain-scrap/pkg/pagination"
 	"blockchain-scrap/repository"
 	"context"
 	"encoding/json"
-	"os"
+	"log"
 	"sync"
 	"time"
 
@@ -17,25 +18,41 @@ import (
 )
 
 type BlockchainService interface {
-	GetBlockchainDetailByContractAddress(ctx context.Context, contractAddress string, timeSkip time.Duration) (*dto.ContractAddressResponse, errs.MessageErr)
-	GetAllBlockchains() ([]map[string]interface{}, errs.MessageErr)
-	GetBlockchainDetailByContractAddressAndID(id, contractAddress string, timeSkip time.Duration) (*dto.ContractAddressResponse, errs.MessageErr)
-	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*dto.BlockchainSearchResponse, errs.MessageErr)
-	FindByID(ctx context.Context, ID uuid.UUID) (*dto.ContractAddressResponse, errs.MessageErr)
+	GetBlockchainDetailByContractAddress(ctx context.Context, userID uuid.UUID, contractAddress string, chart dto.ChartOptions, summary dto.SummaryOptions) (*dto.ContractAddressResponse, errs.MessageErr)
+	GetAllBlockchains(ctx context.Context) ([]map[string]interface{}, errs.MessageErr)
+	GetBlockchainDetailByContractAddressAndID(ctx context.Context, id, contractAddress string, chart dto.ChartOptions, summary dto.SummaryOptions) (*dto.ContractAddressResponse, errs.MessageErr)
+	FindByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*dto.BlockchainSearchList, errs.MessageErr)
+	FindByID(ctx context.Context, userID, ID uuid.UUID) (*dto.ContractAddressResponse, errs.MessageErr)
+	FindSnapshotsByContract(ctx context.Context, userID uuid.UUID, contractAddress string, page pagination.Page) (*dto.BlockchainSearchSnapshotList, errs.MessageErr)
+	GetPriceTicks(ctx context.Context, coinIDs, mints []string) ([]dto.PriceTick, errs.MessageErr)
+	GetTokenRisk(ctx context.Context, address string) (*dto.TokenRisk, errs.MessageErr)
+	// StreamBlockchainDetailByContractAddress passes the lookup to snapshot as soon as its market data is
+	// ready, along with whether the summary backend streams, then streams the summary to chunk as it is
+	// generated and returns the completed lookup
+	StreamBlockchainDetailByContractAddress(ctx context.Context, userID uuid.UUID, contractAddress string, chart dto.ChartOptions, summary dto.SummaryOptions, snapshot func(response *dto.ContractAddressResponse, streaming bool), chunk func(string)) (*dto.ContractAddressResponse, errs.MessageErr)
 }
 
+// solanaPlatformID is the asset platform and chain ID of Solana tokens on CoinGecko and DexScreener
+const solanaPlatformID = "solana"
+
+// tokenAnalyticsTimeout bounds the on-chain holder analysis of a single lookup
+const tokenAnalyticsTimeout = 20 * time.Second
+
 type blockchainService struct {
 	searchRepo repository.BlockchainSearchRepository
 	tokenRepo  repository.TokenRepository
+	marketData MarketDataProvider
+	analyzer   TokenAnalyzer
+	summaries  SummaryService
 }
 
-func NewBlockchainService(searchRepo repository.BlockchainSearchRepository, tokenRepo repository.TokenRepository) BlockchainService {
-	return &blockchainService{searchRepo: searchRepo, tokenRepo: tokenRepo}
+func NewBlockchainService(searchRepo repository.BlockchainSearchRepository, tokenRepo repository.TokenRepository, marketData MarketDataProvider, analyzer TokenAnalyzer, summaries SummaryService) BlockchainService {
+	return &blockchainService{searchRepo: searchRepo, tokenRepo: tokenRepo, marketData: marketData, analyzer: analyzer, summaries: summaries}
 }
 
-// FindByID implements BlockchainService.
-func (s *blockchainService) FindByID(ctx context.Context, ID uuid.UUID) (*dto.ContractAddressResponse, errs.MessageErr) {
-	search, err := s.searchRepo.FindByID(ctx, ID)
+// FindByID implements BlockchainService. Searches of other users are not found.
+func (s *blockchainService) FindByID(ctx context.Context, userID, ID uuid.UUID) (*dto.ContractAddressResponse, errs.MessageErr) {
+	search, err := s.searchRepo.FindByID(ctx, userID, ID)
 	if err != nil {
 		return nil, errs.NewNotFound("Data not found")
 	}
@@ -48,68 +65,109 @@ func (s *blockchainService) FindByID(ctx context.Context, ID uuid.UUID) (*dto.Co
 	return response, nil
 }
 
-func (s *blockchainService) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*dto.BlockchainSearchResponse, errs.MessageErr) {
-	searches, err := s.searchRepo.FindByUserID(ctx, userID)
+func (s *blockchainService) FindByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*dto.BlockchainSearchList, errs.MessageErr) {
+	result, err := s.searchRepo.FindByUserID(ctx, userID, page)
 	if err != nil {
-		return nil, errs.NewNotFound("Data not found")
+		return nil, err
 	}
 
-	responses := make([]*dto.BlockchainSearchResponse, 0, len(searches))
-	for _, search := range searches {
+	responses := make([]*dto.BlockchainSearchResponse, 0, len(result.Items))
+	for _, search := range result.Items {
 		responses = append(responses, &dto.BlockchainSearchResponse{
 			ID:              search.ID,
 			ContractAddress: search.ContractAddress,
+			CreatedAt:       search.CreatedAt,
 		})
 	}
 
-	return responses, nil
+	return &dto.BlockchainSearchList{
+		Searches: responses,
+		CursorPage: dto.CursorPage{
+			NextCursor: result.NextCursor,
+			PrevCursor: result.PrevCursor,
+			Total:      result.Total,
+		},
+	}, nil
 }
 
-func (s *blockchainService) GetBlockchainDetailByContractAddressAndID(id, contractAddress string, timeSkip time.Duration) (*dto.ContractAddressResponse, errs.MessageErr) {
+// FindSnapshotsByContract returns one page of the stored lookups of a contract address by a user, newest first
+func (s *blockchainService) FindSnapshotsByContract(ctx context.Context, userID uuid.UUID, contractAddress string, page pagination.Page) (*dto.BlockchainSearchSnapshotList, errs.MessageErr) {
+	result, err := s.searchRepo.FindByUserIDAndContract(ctx, userID, contractAddress, page)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*dto.BlockchainSearchSnapshot, 0, len(result.Items))
+	for _, search := range result.Items {
+		response := &dto.ContractAddressResponse{}
+		if err := json.Unmarshal(search.ResponseData, response); err != nil {
+			return nil, errs.NewInternalServerError("Failed to process data")
+		}
+		snapshots = append(snapshots, &dto.BlockchainSearchSnapshot{
+			ID:              search.ID,
+			ContractAddress: search.ContractAddress,
+			CreatedAt:       search.CreatedAt,
+			Response:        response,
+		})
+	}
+
+	return &dto.BlockchainSearchSnapshotList{
+		Snapshots: snapshots,
+		CursorPage: dto.CursorPage{
+			NextCursor: result.NextCursor,
+			PrevCursor: result.PrevCursor,
+			Total:      result.Total,
+		},
+	}, nil
+}
+
+func (s *blockchainService) GetBlockchainDetailByContractAddressAndID(ctx context.Context, id, contractAddress string, chart dto.ChartOptions, summary dto.SummaryOptions) (*dto.ContractAddressResponse, errs.MessageErr) {
 	var (
-		response      = &dto.ContractAddressResponse{}
-		prices        = &dto.GetPricesRequest{}
-		liquidity     = []dto.GetLiquidityRequest{}
-		errChan       = make(chan error, 3)
-		wg            sync.WaitGroup
-		contractResp  []byte
-		marketResp    []byte
-		liquidityResp []byte
-		aiResp        []byte
+		response  *dto.ContractAddressResponse
+		prices    *dto.GetPricesRequest
+		liquidity []dto.GetLiquidityRequest
+		analytics = &dto.TokenAnalytics{}
+		errChan   = make(chan error, 3)
+		wg        sync.WaitGroup
 	)
 
+	if id == solanaPlatformID {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			analytics = s.analyzeToken(ctx, contractAddress, nil)
+		}()
+	}
+
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		url := "https://api.coingecko.com/api/v3/coins/" + id + "/contract/" + contractAddress
-		body, err := httprequest.ProcessJSONRequest("GET", url, nil, nil)
+		result, err := s.marketData.GetCoinByContract(ctx, id, contractAddress)
 		if err != nil {
 			errChan <- err
 			return
 		}
-		contractResp = body
+		response = result
 	}()
 
 	go func() {
 		defer wg.Done()
-		url := "https://api.coingecko.com/api/v3/coins/" + id + "/market_chart?vs_currency=usd&days=1"
-		body, err := httprequest.ProcessJSONRequest("GET", url, nil, nil)
+		result, err := s.marketData.GetMarketChart(ctx, id, chartDays(chart.Range))
 		if err != nil {
 			errChan <- err
 			return
 		}
-		marketResp = body
+		prices = result
 	}()
 
 	go func() {
 		defer wg.Done()
-		url := "https://api.dexscreener.com/tokens/v1/" + id + "/" + contractAddress
-		body, err := httprequest.ProcessJSONRequest("GET", url, nil, nil)
+		result, err := s.marketData.GetDexPairs(ctx, id, contractAddress)
 		if err != nil {
 			errChan <- err
 			return
 		}
-		liquidityResp = body
+		liquidity = result
 	}()
 
 	wg.Wait()
@@ -122,40 +180,11 @@ func (s *blockchainService) GetBlockchainDetailByContractAddressAndID(id, contra
 	}
 
 	response.Platform = contractAddress
-	if err := json.Unmarshal(contractResp, response); err != nil {
-		return nil, errs.NewInternalServerError("Failed to process contract data")
-	}
-
-	if err := json.Unmarshal(marketResp, prices); err != nil {
-		return nil, errs.NewInternalServerError("Failed to process price data")
-	}
-
-	if err := json.Unmarshal(liquidityResp, &liquidity); err != nil {
-		return nil, errs.NewInternalServerError("Failed to process liquidity data")
-	}
 
-	if len(liquidity) > 0 {
-		response.MarketData.Liquidity.USD = liquidity[0].Liquidity.USD
-	}
+	response.LiquidityInfo = buildDexLiquidityInfo(liquidity, marketCapOrFDV(response.MarketData))
+	response.MarketData.Liquidity.USD = response.LiquidityInfo.LiquidityPoolSize
 
-	// Filter time stamp in chart
-	var filteredPrices []dto.PricePoint
-	var lastTime time.Time
-
-	for i, item := range prices.Prices {
-		timestampMs := int64(item[0])
-		price := item[1]
-		t := time.UnixMilli(timestampMs)
-
-		if i == 0 || t.Sub(lastTime) >= timeSkip {
-			filteredPrices = append(filteredPrices, dto.PricePoint{
-				Timestamp: t.Format(time.RFC3339),
-				Price:     price,
-			})
-			lastTime = t
-		}
-	}
-	response.TimePrices = filteredPrices
+	applyChart(response, prices, chart)
 
 	if response.Symbol == "" {
 		return nil, errs.NewNotFound("Contract address not found")
@@ -165,66 +194,67 @@ func (s *blockchainService) GetBlockchainDetailByContractAddressAndID(id, contra
 		return nil, errs.NewNotFound("Token ID not found")
 	}
 
-	// Default seed data
-	liquidityInfo := &dto.DexLiquidityInfo{
-		LiquidityPoolSize: 67.8,
-		TopDex:            "Jupiter",
-		Volume24h:         130.0,
-		SlippageNote:      "Low (<0.5%)",
-		DexLiquidityRatio: 4.6,
-		LiquidityTrend7D:  8.2,
+	response.TokenAnalytics = *analytics
+	response.ListingDay, response.ListingSource = resolveListingDay(nil, response.GenesisDate, liquidity, time.Now())
+	if id == solanaPlatformID {
+		if tokens, errToken := s.tokenRepo.FindByAddress([]string{contractAddress}); errToken == nil && len(tokens) > 0 {
+			response.Risk = tokenRisk(tokens[0], response.LiquidityInfo, analytics)
+		}
 	}
 
-	tokenAnalytics := &dto.TokenAnalytics{
-		TopHolder:   8.568,
-		TopWallets:  8.568,
-		TokenViewer: 8.568,
-		SniperBot:   1.576,
-		DevSold:     true,
-		DevBuyback:  true,
+	s.addSummary(ctx, response, PromptCoinSummary, summary)
+
+	return response, nil
+}
+
+func (s *blockchainService) GetBlockchainDetailByContractAddress(ctx context.Context, userID uuid.UUID, contractAddress string, chart dto.ChartOptions, summary dto.SummaryOptions) (*dto.ContractAddressResponse, errs.MessageErr) {
+	response, err := s.lookupContractAddress(ctx, contractAddress, chart)
+	if err != nil {
+		return nil, err
 	}
-	response.LiquidityInfo = *liquidityInfo
-	response.TokenAnalytics = *tokenAnalytics
-	response.ListingDay = 575
 
-	aiReq := &dto.AIRequest{
-		Prompt:      "Analyze the following crypto data and summarize it in English. Also give suggestions on what the potential of this crypto is. All currency data is also in USD. Summarize only the important data. If there is any empty or meaningless data, just ignore it, it does not need to be in the output.",
-		Collections: *response,
+	s.addSummary(ctx, response, PromptTokenSummary, summary)
+
+	if userID != uuid.Nil {
+		s.recordSearch(ctx, userID, response)
 	}
 
-	aiReqBody, err := json.Marshal(aiReq)
+	return response, nil
+}
+
+// StreamBlockchainDetailByContractAddress implements BlockchainService.
+func (s *blockchainService) StreamBlockchainDetailByContractAddress(ctx context.Context, userID uuid.UUID, contractAddress string, chart dto.ChartOptions, summary dto.SummaryOptions, snapshot func(response *dto.ContractAddressResponse, streaming bool), chunk func(string)) (*dto.ContractAddressResponse, errs.MessageErr) {
+	response, err := s.lookupContractAddress(ctx, contractAddress, chart)
 	if err != nil {
-		return nil, errs.NewInternalServerError("Failed to process data for AI")
+		return nil, err
 	}
 
-	url := "https://casandra-bot.athenor.id/api/preset/completions"
-	header := map[string]string{
-		"ATHENOR-API-KEY": os.Getenv("API_KEY"),
+	source := summary.Source
+	if source == "" {
+		source = s.summaries.DefaultSource()
 	}
-	body, _ := httprequest.ProcessJSONRequest("POST", url, aiReqBody, header)
-	aiResp = body
+	response.SummarySource = source
+	response.SummaryStatus = dto.SummaryStatusPending
+	snapshot(response, s.summaries.Streams(source))
 
-	aiResponse := &dto.AIResponse{}
-	if err = json.Unmarshal(aiResp, aiResponse); err != nil {
-		return nil, errs.NewInternalServerError("Failed to process AI response")
-	}
+	req := SummaryRequest{Source: source, Prompt: PromptTokenSummary, Language: summary.Language, Data: response}
+	response.SummaryAnalysis, response.SummaryStatus = s.summaries.Stream(ctx, req, chunk)
 
-	response.SummaryAnalysis = aiResponse.AssistantMessage
+	if userID != uuid.Nil {
+		s.recordSearch(ctx, userID, response)
+	}
 
 	return response, nil
 }
 
-func (s *blockchainService) GetBlockchainDetailByContractAddress(ctx context.Context, contractAddress string, timeSkip time.Duration) (*dto.ContractAddressResponse, errs.MessageErr) {
+// lookupContractAddress gathers the market data, liquidity, holder analytics and risk of a Solana token
+func (s *blockchainService) lookupContractAddress(ctx context.Context, contractAddress string, chart dto.ChartOptions) (*dto.ContractAddressResponse, errs.MessageErr) {
 	var (
-		response      = &dto.ContractAddressResponse{}
-		prices        = &dto.GetPricesRequest{}
-		liquidity     = []dto.GetLiquidityRequest{}
-		errChan       = make(chan error, 2)
-		wg            sync.WaitGroup
-		contractResp  []byte
-		marketResp    []byte
-		liquidityResp []byte
-		aiResp        []byte
+		prices    *dto.GetPricesRequest
+		liquidity []dto.GetLiquidityRequest
+		analytics *dto.TokenAnalytics
+		errChan   = make(chan error, 2)
+		wg        sync.WaitGroup
 	)
 
 	token, err := s.tokenRepo.FindByAddress([]string{contractAddress})
@@ -237,39 +267,36 @@ func (s *blockchainService) GetBlockchainDetailByContractAddress(ctx context.Con
 		return nil, errs.NewNotFound("Contract address not found, please change different contract address")
 	}
 
-	url := "https://api.coingecko.com/api/v3/coins/id/contract/" + contractAddress
-	body, _ := httprequest.ProcessJSONRequest("GET", url, nil, nil)
-
-	contractResp = body
-	response.Platform = contractAddress
-	if err := json.Unmarshal(contractResp, response); err != nil {
-		return nil, errs.NewInternalServerError("Failed to process contract data")
-	}
-	if response.Symbol == "" {
+	response, err := s.marketData.GetCoinByContract(ctx, solanaPlatformID, contractAddress)
+	if err != nil || response.Symbol == "" {
 		return nil, errs.NewNotFound("Contract address not found, please change different contract address")
 	}
+	response.Platform = contractAddress
 
-	wg.Add(2)
+	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		url := "https://api.coingecko.com/api/v3/coins/" + response.ID + "/market_chart?vs_currency=usd&days=1"
-		body, err := httprequest.ProcessJSONRequest("GET", url, nil, nil)
+		analytics = s.analyzeToken(ctx, contractAddress, token[0])
+	}()
+
+	go func() {
+		defer wg.Done()
+		result, err := s.marketData.GetMarketChart(ctx, response.ID, chartDays(chart.Range))
 		if err != nil {
 			errChan <- err
 			return
 		}
-		marketResp = body
+		prices = result
 	}()
 
 	go func() {
 		defer wg.Done()
-		url := "https://api.dexscreener.com/tokens/v1/" + response.ID + "/" + contractAddress
-		body, err := httprequest.ProcessJSONRequest("GET", url, nil, nil)
+		result, err := s.marketData.GetDexPairs(ctx, solanaPlatformID, contractAddress)
 		if err != nil {
 			errChan <- err
 			return
 		}
-		liquidityResp = body
+		liquidity = result
 	}()
 
 	wg.Wait()
@@ -281,114 +308,112 @@ func (s *blockchainService) GetBlockchainDetailByContractAddress(ctx context.Con
 		}
 	}
 
-	if err := json.Unmarshal(marketResp, prices); err != nil {
-		return nil, errs.NewInternalServerError("Failed to process price data")
-	}
+	response.LiquidityInfo = buildDexLiquidityInfo(liquidity, marketCapOrFDV(response.MarketData))
+	response.MarketData.Liquidity.USD = response.LiquidityInfo.LiquidityPoolSize
 
-	if err := json.Unmarshal(liquidityResp, &liquidity); err != nil {
-		return nil, errs.NewInternalServerError(err.Error())
-	}
+	applyChart(response, prices, chart)
 
-	if len(liquidity) > 0 {
-		response.MarketData.Liquidity.USD = liquidity[0].Liquidity.USD
+	if len(response.TimePrices) == 0 {
+		return nil, errs.NewNotFound("Contract address not found, please change different contract address")
 	}
 
-	// Filter time stamp in chart
-	var filteredPrices []dto.PricePoint
-	var lastTime time.Time
-
-	for i, item := range prices.Prices {
-		timestampMs := int64(item[0])
-		price := item[1]
-		t := time.UnixMilli(timestampMs)
+	response.TokenAnalytics = *analytics
+	response.ListingDay, response.ListingSource = resolveListingDay(token[0], response.GenesisDate, liquidity, time.Now())
+	response.Risk = tokenRisk(token[0], response.LiquidityInfo, analytics)
 
-		if i == 0 || t.Sub(lastTime) >= timeSkip {
-			filteredPrices = append(filteredPrices, dto.PricePoint{
-				Timestamp: t.Format(time.RFC3339),
-				Price:     price,
-			})
-			lastTime = t
-		}
-	}
-	response.TimePrices = filteredPrices
+	return response, nil
+}
 
-	if len(response.TimePrices) == 0 {
-		return nil, errs.NewNotFound("Contract address not found, please change different contract address")
-	}
+func (s *blockchainService) GetAllBlockchains(ctx context.Context) ([]map[string]interface{}, errs.MessageErr) {
+	return s.marketData.GetMarkets(ctx)
+}
 
-	// Default seed data
-	liquidityInfo := &dto.DexLiquidityInfo{
-		LiquidityPoolSize: 67.8,
-		TopDex:            "Jupiter",
-		Volume24h:         130.0,
-		SlippageNote:      "Low (<0.5%)",
-		DexLiquidityRatio: 4.6,
-		LiquidityTrend7D:  8.2,
+// addSummary adds the summary analysis to a lookup in the requested mode
+func (s *blockchainService) addSummary(ctx context.Context, response *dto.ContractAddressResponse, prompt string, options dto.SummaryOptions) {
+	if options.Mode == dto.SummaryModeSkip {
+		response.SummaryStatus = dto.SummaryStatusSkipped
+		return
 	}
 
-	tokenAnalytics := &dto.TokenAnalytics{
-		TopHolder:   8.568,
-		TopWallets:  8.568,
-		TokenViewer: 8.568,
-		SniperBot:   1.576,
-		DevSold:     true,
-		DevBuyback:  true,
+	source := options.Source
+	if source == "" {
+		source = s.summaries.DefaultSource()
 	}
-	response.LiquidityInfo = *liquidityInfo
-	response.TokenAnalytics = *tokenAnalytics
-	response.ListingDay = 575
+	response.SummarySource = source
 
-	aiReq := &dto.AIRequest{
-		Prompt:      "Analyze the following crypto data and summarize it in English. Also provide suggestions on the potential of this crypto. All currency data is also in USD. Summarize only the important data. If there is empty or meaningless data, ignore it, because it does not need to be included in the output. Go straight to the core of the analysis, suggestions and recommendations, a maximum of 1 paragraph that already covers the most important.",
-		Collections: *response,
+	req := SummaryRequest{Source: source, Prompt: prompt, Language: options.Language, Data: response}
+	switch options.Mode {
+	case dto.SummaryModeAsync:
+		id, ok := s.summaries.Enqueue(req)
+		if !ok {
+			response.SummaryStatus = dto.SummaryStatusSkipped
+			return
+		}
+		response.SummaryID = &id
+		response.SummaryStatus = dto.SummaryStatusPending
+	default:
+		response.SummaryAnalysis, response.SummaryStatus = s.summaries.Summarize(ctx, req)
 	}
+}
 
-	aiReqBody, errMarshal := json.Marshal(aiReq)
+// recordSearch stores a snapshot of a contract lookup in the user's search history. Failures are
+// only logged, the lookup is returned either way.
+func (s *blockchainService) recordSearch(ctx context.Context, userID uuid.UUID, response *dto.ContractAddressResponse) {
+	jsonData, errMarshal := json.Marshal(response)
 	if errMarshal != nil {
-		return nil, errs.NewInternalServerError("Failed to process data for AI")
+		log.Println("Failed to process search data for storage:", response.Platform, errMarshal)
+		return
 	}
 
-	url = "https://casandra-bot.athenor.id/api/preset/completions"
-	header := map[string]string{
-		"ATHENOR-API-KEY": os.Getenv("API_KEY"),
+	searchRecord := &entity.BlockchainSearch{
+		ID:              uuid.New(),
+		UserID:          userID,
+		ContractAddress: response.Platform,
+		ResponseData:    jsonData,
 	}
-	body, _ = httprequest.ProcessJSONRequest("POST", url, aiReqBody, header)
-	aiResp = body
-
-	aiResponse := &dto.AIResponse{}
-	if errUnmarshal := json.Unmarshal(aiResp, aiResponse); errUnmarshal != nil {
-		return nil, errs.NewInternalServerError("Failed to process AI response")
+	if err := s.searchRepo.Save(ctx, searchRecord); err != nil {
+		log.Println("Failed to save search data:", response.Platform, err.Message())
 	}
-	response.SummaryAnalysis = aiResponse.AssistantMessage
-
-	// jsonData, errMarshal := json.Marshal(response)
-	// if errMarshal != nil {
-	// 	return nil, errs.NewInternalServerError("Failed to process data for storage")
-	// }
-
-	// searchRecord := &entity.BlockchainSearch{
-	// 	ID:              uuid.New(),
-	// 	UserID:          userID,
-	// 	ContractAddress: response.Platform,
-	// 	ResponseData:    jsonData,
-	// }
-	// err = s.searchRepo.SaveOrUpdate(ctx, searchRecord)
-	// if err != nil {
-	// 	return nil, errs.NewInternalServerError("Failed to save search data")
-	// }
-
-	return response, nil
 }
 
-func (s *blockchainService) GetAllBlockchains() ([]map[string]interface{}, errs.MessageErr) {
-	url := "https://api.coingecko.com/api/v3/coins/markets?vs_currency=usd&order=market_cap_desc&per_page=100&page=1"
-	body, err := httprequest.ProcessJSONRequest("GET", url, nil, nil)
+// analyzeToken runs the on-chain holder analysis of a mint, returning empty analytics when it fails
+func (s *blockchainService) analyzeToken(ctx context.Context, mint string, token *entity.Token) *dto.TokenAnalytics {
+	ctx, cancel := context.WithTimeout(ctx, tokenAnalyticsTimeout)
+	defer cancel()
+
+	analytics, err := s.analyzer.Analyze(ctx, mint, token)
 	if err != nil {
-		return nil, errs.NewInternalServerError("Failed to fetch blockchain data")
+		log.Println("Failed to analyze token holders:", mint, err.Message())
+		return &dto.TokenAnalytics{}
 	}
-	var coins []map[string]interface{}
-	if err := json.Unmarshal(body, &coins); err != nil {
-		return nil, errs.NewInternalServerError("Failed to process blockchain data")
+	return analytics
+}
+
+// marketCapOrFDV returns the market cap, falling back to the fully diluted valuation when it is unknown
+func marketCapOrFDV(marketData dto.MarketData) float64 {
+	if marketData.MarketCap.USD > 0 {
+		return marketData.MarketCap.USD
+	}
+	return marketData.FullyDilutedValuation.USD
+}
+
+// filterPricePoints down-samples market chart prices so consecutive points are at least timeSkip apart
+func filterPricePoints(prices [][]float64, timeSkip time.Duration) []dto.PricePoint {
+	var filteredPrices []dto.PricePoint
+	var lastTime time.Time
+
+	for i, item := range prices {
+		timestampMs := int64(item[0])
+		price := item[1]
+		t := time.UnixMilli(timestampMs)
+
+		if i == 0 || t.Sub(lastTime) >= timeSkip {
+			filteredPrices = append(filteredPrices, dto.PricePoint{
+				Timestamp: t.Format(time.RFC3339),
+				Price:     price,
+			})
+			lastTime = t
+		}
 	}
-	return coins, nil
+	return filteredPrices
 }
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const testContractAddress = "0x6b175474e89094c44da98b954eedeac495271d0f"
+
+func newTestMarketData() FakeMarketData {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	step := (5 * time.Minute).Milliseconds()
+
+	coin := dto.ContractAddressResponse{ID: "dai", Symbol: "dai", GenesisDate: "2019-11-13"}
+	coin.MarketData.CurrentPrice.USD = 1
+	coin.MarketData.MarketCap.USD = 5_000_000
+
+	return FakeMarketData{
+		Coins: map[string]dto.ContractAddressResponse{testContractAddress: coin},
+		Charts: map[string]dto.GetPricesRequest{"ethereum": {Prices: [][]float64{
+			{float64(start), 0.99},
+			{float64(start + step), 1.00},
+			{float64(start + 2*step), 1.01},
+		}}},
+		Pairs: map[string][]dto.GetLiquidityRequest{testContractAddress: {
+			{DexID: "uniswap", Liquidity: dto.CurrencyValue{USD: 400_000}, Volume: dto.DexVolume{H24: 20_000}},
+			{DexID: "curve", Liquidity: dto.CurrencyValue{USD: 100_000}, Volume: dto.DexVolume{H24: 5_000}},
+		}},
+	}
+}
+
+func TestGetBlockchainDetailByContractAddressAndID(t *testing.T) {
+	service := NewBlockchainService(nil, nil, NewFakeMarketDataProvider(newTestMarketData()), nil, nil)
+	chart, errChart := NewChartOptions("", "", "")
+	if errChart != nil {
+		t.Fatalf("NewChartOptions: %s", errChart.Message())
+	}
+
+	response, err := service.GetBlockchainDetailByContractAddressAndID(context.Background(), "ethereum", testContractAddress,
+		chart, dto.SummaryOptions{Mode: dto.SummaryModeSkip})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Message())
+	}
+
+	if response.Platform != testContractAddress {
+		t.Errorf("Platform = %q, want %q", response.Platform, testContractAddress)
+	}
+	if len(response.TimePrices) != 3 {
+		t.Errorf("len(TimePrices) = %d, want 3", len(response.TimePrices))
+	}
+
+	liquidity := response.LiquidityInfo
+	if liquidity.PoolCount != 2 || liquidity.LiquidityPoolSize != 500_000 || liquidity.Volume24h != 25_000 {
+		t.Errorf("LiquidityInfo = %+v, want 2 pools, $500000 liquidity and $25000 volume", liquidity)
+	}
+	if liquidity.TopDex != "uniswap" {
+		t.Errorf("TopDex = %q, want uniswap", liquidity.TopDex)
+	}
+	if liquidity.DexLiquidityRatio != 10 {
+		t.Errorf("DexLiquidityRatio = %g, want 10", liquidity.DexLiquidityRatio)
+	}
+	if response.MarketData.Liquidity.USD != 500_000 {
+		t.Errorf("MarketData.Liquidity = %g, want 500000", response.MarketData.Liquidity.USD)
+	}
+	if response.SummaryStatus != dto.SummaryStatusSkipped {
+		t.Errorf("SummaryStatus = %q, want %q", response.SummaryStatus, dto.SummaryStatusSkipped)
+	}
+}
+
+func TestGetBlockchainDetailByContractAddressAndIDUnknownContract(t *testing.T) {
+	service := NewBlockchainService(nil, nil, NewFakeMarketDataProvider(newTestMarketData()), nil, nil)
+	chart, _ := NewChartOptions("", "", "")
+
+	_, err := service.GetBlockchainDetailByContractAddressAndID(context.Background(), "ethereum", "0xunknown",
+		chart, dto.SummaryOptions{Mode: dto.SummaryModeSkip})
+	if err == nil {
+		t.Fatal("expected an error for an unknown contract address")
+	}
+	if err.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("StatusCode = %d, want %d", err.StatusCode(), http.StatusInternalServerError)
+	}
+}
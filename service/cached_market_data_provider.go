@@ -0,0 +1,144 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/cache"
+	"blockchain-scrap/pkg/config"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// MarketDataCacheTTLs holds how long each kind of upstream response stays cached
+type MarketDataCacheTTLs struct {
+	CoinDetail  time.Duration
+	MarketChart time.Duration
+	Markets     time.Duration
+	DexPairs    time.Duration
+	TokenPrices time.Duration
+}
+
+// MarketDataCacheTTLsFromEnv reads the cache TTLs from CACHE_TTL_COIN_DETAIL, CACHE_TTL_MARKET_CHART,
+// CACHE_TTL_MARKETS, CACHE_TTL_DEX_PAIRS and CACHE_TTL_TOKEN_PRICES, using defaults sized for the CoinGecko free tier
+func MarketDataCacheTTLsFromEnv() MarketDataCacheTTLs {
+	return MarketDataCacheTTLs{
+		CoinDetail:  config.Duration("CACHE_TTL_COIN_DETAIL", 2*time.Minute),
+		MarketChart: config.Duration("CACHE_TTL_MARKET_CHART", 5*time.Minute),
+		Markets:     config.Duration("CACHE_TTL_MARKETS", 30*time.Second),
+		DexPairs:    config.Duration("CACHE_TTL_DEX_PAIRS", time.Minute),
+		TokenPrices: config.Duration("CACHE_TTL_TOKEN_PRICES", 30*time.Second),
+	}
+}
+
+// cachedMarketDataProvider decorates a MarketDataProvider with a response cache
+type cachedMarketDataProvider struct {
+	next  MarketDataProvider
+	cache *cache.Cache
+	ttls  MarketDataCacheTTLs
+}
+
+// NewCachedMarketDataProvider creates a MarketDataProvider that serves repeated calls from c
+func NewCachedMarketDataProvider(next MarketDataProvider, c *cache.Cache, ttls MarketDataCacheTTLs) MarketDataProvider {
+	return &cachedMarketDataProvider{next: next, cache: c, ttls: ttls}
+}
+
+// GetCoinByContract implements MarketDataProvider.
+func (p *cachedMarketDataProvider) GetCoinByContract(ctx context.Context, platformID, contractAddress string) (*dto.ContractAddressResponse, errs.MessageErr) {
+	response := &dto.ContractAddressResponse{}
+	err := p.load(ctx, cacheKey("coin", platformID, contractAddress), p.ttls.CoinDetail, response, func(ctx context.Context) (interface{}, errs.MessageErr) {
+		return p.next.GetCoinByContract(ctx, platformID, contractAddress)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetMarketChart implements MarketDataProvider.
+func (p *cachedMarketDataProvider) GetMarketChart(ctx context.Context, coinID, days string) (*dto.GetPricesRequest, errs.MessageErr) {
+	prices := &dto.GetPricesRequest{}
+	err := p.load(ctx, cacheKey("chart", coinID, days), p.ttls.MarketChart, prices, func(ctx context.Context) (interface{}, errs.MessageErr) {
+		return p.next.GetMarketChart(ctx, coinID, days)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// GetMarkets implements MarketDataProvider.
+func (p *cachedMarketDataProvider) GetMarkets(ctx context.Context) ([]map[string]interface{}, errs.MessageErr) {
+	var coins []map[string]interface{}
+	err := p.load(ctx, cacheKey("markets"), p.ttls.Markets, &coins, func(ctx context.Context) (interface{}, errs.MessageErr) {
+		return p.next.GetMarkets(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coins, nil
+}
+
+// GetMarketsByIDs implements MarketDataProvider.
+func (p *cachedMarketDataProvider) GetMarketsByIDs(ctx context.Context, coinIDs []string) ([]map[string]interface{}, errs.MessageErr) {
+	var coins []map[string]interface{}
+	err := p.load(ctx, cacheKey("markets", strings.Join(coinIDs, ",")), p.ttls.Markets, &coins, func(ctx context.Context) (interface{}, errs.MessageErr) {
+		return p.next.GetMarketsByIDs(ctx, coinIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coins, nil
+}
+
+// GetTokenPrices implements MarketDataProvider.
+func (p *cachedMarketDataProvider) GetTokenPrices(ctx context.Context, platformID string, contractAddresses []string) (map[string]dto.TokenPrice, errs.MessageErr) {
+	prices := map[string]dto.TokenPrice{}
+	err := p.load(ctx, cacheKey("tokenprice", platformID, strings.Join(contractAddresses, ",")), p.ttls.TokenPrices, &prices, func(ctx context.Context) (interface{}, errs.MessageErr) {
+		return p.next.GetTokenPrices(ctx, platformID, contractAddresses)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// GetDexPairs implements MarketDataProvider.
+func (p *cachedMarketDataProvider) GetDexPairs(ctx context.Context, chainID, tokenAddress string) ([]dto.GetLiquidityRequest, errs.MessageErr) {
+	pairs := []dto.GetLiquidityRequest{}
+	err := p.load(ctx, cacheKey("dex", chainID, tokenAddress), p.ttls.DexPairs, &pairs, func(ctx context.Context) (interface{}, errs.MessageErr) {
+		return p.next.GetDexPairs(ctx, chainID, tokenAddress)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// load serves key from the cache, calling fetch on a miss, and decodes the JSON value into out
+func (p *cachedMarketDataProvider) load(ctx context.Context, key string, ttl time.Duration, out interface{}, fetch func(ctx context.Context) (interface{}, errs.MessageErr)) errs.MessageErr {
+	body, err := p.cache.GetOrLoad(ctx, key, ttl, func(ctx context.Context) ([]byte, error) {
+		result, errFetch := fetch(ctx)
+		if errFetch != nil {
+			return nil, errFetch
+		}
+		return json.Marshal(result)
+	})
+	if err != nil {
+		if messageErr, ok := err.(errs.MessageErr); ok {
+			return messageErr
+		}
+		return errs.NewInternalServerError("Failed to fetch market data: " + err.Error())
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return errs.NewInternalServerError("Failed to process cached market data")
+	}
+	return nil
+}
+
+// cacheKey builds a namespaced cache key for an upstream market data call
+func cacheKey(parts ...string) string {
+	return "marketdata:" + strings.Join(parts, ":")
+}
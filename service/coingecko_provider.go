@@ -0,0 +1,132 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	httprequest "blockchain-scrap/pkg/http-request"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+const defaultCoinGeckoBaseURL = "https://api.coingecko.com/api/v3"
+
+// coinGeckoProvider implements CoinMarketSource using the public CoinGecko API
+type coinGeckoProvider struct {
+	baseURL string
+}
+
+// NewCoinGeckoProvider creates a CoinMarketSource for CoinGecko, falling back to the public API when baseURL is empty
+func NewCoinGeckoProvider(baseURL string) CoinMarketSource {
+	if baseURL == "" {
+		baseURL = defaultCoinGeckoBaseURL
+	}
+	return &coinGeckoProvider{baseURL: baseURL}
+}
+
+// GetCoinByContract fetches coin details for a contract address on the given asset platform
+func (p *coinGeckoProvider) GetCoinByContract(ctx context.Context, platformID, contractAddress string) (*dto.ContractAddressResponse, errs.MessageErr) {
+	endpoint := p.baseURL + "/coins/" + url.PathEscape(platformID) + "/contract/" + url.PathEscape(contractAddress)
+	body, err := httprequest.ProcessJSONRequestWithContext(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ContractAddressResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, errs.NewInternalServerError("Failed to process contract data")
+	}
+	return response, nil
+}
+
+// GetMarketChart fetches the USD market chart of a coin for the given number of days
+func (p *coinGeckoProvider) GetMarketChart(ctx context.Context, coinID, days string) (*dto.GetPricesRequest, errs.MessageErr) {
+	query := url.Values{}
+	query.Set("vs_currency", "usd")
+	query.Set("days", days)
+
+	endpoint := p.baseURL + "/coins/" + url.PathEscape(coinID) + "/market_chart?" + query.Encode()
+	body, err := httprequest.ProcessJSONRequestWithContext(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := &dto.GetPricesRequest{}
+	if err := json.Unmarshal(body, prices); err != nil {
+		return nil, errs.NewInternalServerError("Failed to process price data")
+	}
+	return prices, nil
+}
+
+// GetMarkets fetches the top 100 coins ordered by market cap
+func (p *coinGeckoProvider) GetMarkets(ctx context.Context) ([]map[string]interface{}, errs.MessageErr) {
+	endpoint := p.baseURL + "/coins/markets?vs_currency=usd&order=market_cap_desc&per_page=100&page=1"
+	body, err := httprequest.ProcessJSONRequestWithContext(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch blockchain data")
+	}
+
+	var coins []map[string]interface{}
+	if err := json.Unmarshal(body, &coins); err != nil {
+		return nil, errs.NewInternalServerError("Failed to process blockchain data")
+	}
+	return coins, nil
+}
+
+// GetMarketsByIDs fetches the market data of the given coin IDs
+func (p *coinGeckoProvider) GetMarketsByIDs(ctx context.Context, coinIDs []string) ([]map[string]interface{}, errs.MessageErr) {
+	query := url.Values{}
+	query.Set("vs_currency", "usd")
+	query.Set("ids", strings.Join(coinIDs, ","))
+
+	endpoint := p.baseURL + "/coins/markets?" + query.Encode()
+	body, err := httprequest.ProcessJSONRequestWithContext(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch blockchain data")
+	}
+
+	var coins []map[string]interface{}
+	if err := json.Unmarshal(body, &coins); err != nil {
+		return nil, errs.NewInternalServerError("Failed to process blockchain data")
+	}
+	return coins, nil
+}
+
+// GetTokenPrices fetches the USD price and 24h change of contract addresses on the given asset platform,
+// keyed by the requested address
+func (p *coinGeckoProvider) GetTokenPrices(ctx context.Context, platformID string, contractAddresses []string) (map[string]dto.TokenPrice, errs.MessageErr) {
+	query := url.Values{}
+	query.Set("contract_addresses", strings.Join(contractAddresses, ","))
+	query.Set("vs_currencies", "usd")
+	query.Set("include_24hr_change", "true")
+
+	endpoint := p.baseURL + "/simple/token_price/" + url.PathEscape(platformID) + "?" + query.Encode()
+	body, err := httprequest.ProcessJSONRequestWithContext(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch token prices")
+	}
+
+	var raw map[string]struct {
+		USD          *float64 `json:"usd"`
+		USD24hChange *float64 `json:"usd_24h_change"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, errs.NewInternalServerError("Failed to process token prices")
+	}
+
+	// CoinGecko may change the case of the addresses it returns
+	requested := make(map[string]string, len(contractAddresses))
+	for _, address := range contractAddresses {
+		requested[strings.ToLower(address)] = address
+	}
+	prices := make(map[string]dto.TokenPrice, len(raw))
+	for address, price := range raw {
+		original, ok := requested[strings.ToLower(address)]
+		if !ok || price.USD == nil {
+			continue
+		}
+		prices[original] = dto.TokenPrice{PriceUSD: *price.USD, Change24h: price.USD24hChange, Source: "coingecko"}
+	}
+	return prices, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"math"
+)
+
+// slippageTradeSizes are the USD trade sizes used to estimate price impact against pooled liquidity
+var slippageTradeSizes = []float64{1_000, 10_000, 100_000}
+
+// buildDexLiquidityInfo aggregates DexScreener pairs of a token into DEX liquidity analytics.
+// Pools are assumed to be constant-product with half of their USD liquidity on each side.
+// This is an optimistic approximation: trades are assumed to route across every pool at once and
+// concentrated-liquidity pools report liquidity outside the active range, so real impact can be higher.
+func buildDexLiquidityInfo(pairs []dto.GetLiquidityRequest, marketCap float64) dto.DexLiquidityInfo {
+	info := dto.DexLiquidityInfo{}
+	liquidityByDex := make(map[string]float64)
+
+	for _, pair := range pairs {
+		info.LiquidityPoolSize += pair.Liquidity.USD
+		info.Volume24h += pair.Volume.H24
+		liquidityByDex[pair.DexID] += pair.Liquidity.USD
+	}
+	info.PoolCount = len(pairs)
+
+	for dex, liquidity := range liquidityByDex {
+		if liquidity > info.TopDexLiquidity || (liquidity == info.TopDexLiquidity && dex < info.TopDex) {
+			info.TopDex = dex
+			info.TopDexLiquidity = liquidity
+		}
+	}
+
+	if marketCap > 0 {
+		info.DexLiquidityRatio = roundTo(info.LiquidityPoolSize/marketCap*100, 4)
+	}
+
+	for _, size := range slippageTradeSizes {
+		impact := estimatePriceImpact(info.LiquidityPoolSize, size)
+		info.SlippageEstimates = append(info.SlippageEstimates, dto.SlippageEstimate{
+			TradeSizeUSD:   size,
+			PriceImpactPct: roundTo(impact, 4),
+			Bucket:         slippageBucket(impact),
+		})
+	}
+	info.SlippageNote = slippageBucket(estimatePriceImpact(info.LiquidityPoolSize, slippageTradeSizes[0]))
+
+	return info
+}
+
+// estimatePriceImpact returns the price impact in percent of swapping tradeSize USD
+// into a constant-product pool holding liquidity USD in total
+func estimatePriceImpact(liquidity, tradeSize float64) float64 {
+	if liquidity <= 0 {
+		return 100
+	}
+	reserve := liquidity / 2
+	return tradeSize / (reserve + tradeSize) * 100
+}
+
+// slippageBucket converts a price impact percentage into a human readable note
+func slippageBucket(impact float64) string {
+	switch {
+	case impact < 0.5:
+		return "Low (<0.5%)"
+	case impact < 2:
+		return "Medium (0.5-2%)"
+	case impact < 5:
+		return "High (2-5%)"
+	default:
+		return "Very High (>5%)"
+	}
+}
+
+// roundTo rounds value to the given number of decimal places
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow10(places)
+	return math.Round(value*factor) / factor
+}
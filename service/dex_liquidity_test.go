@@ -0,0 +1,105 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"reflect"
+	"testing"
+)
+
+func TestEstimatePriceImpact(t *testing.T) {
+	tests := []struct {
+		name      string
+		liquidity float64
+		tradeSize float64
+		want      float64
+	}{
+		{name: "no liquidity", liquidity: 0, tradeSize: 1_000, want: 100},
+		{name: "trade equal to the reserve", liquidity: 20_000, tradeSize: 10_000, want: 50},
+		{name: "deep pool", liquidity: 2_000_000, tradeSize: 1_000, want: 0.0999},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundTo(estimatePriceImpact(tt.liquidity, tt.tradeSize), 4); got != tt.want {
+				t.Errorf("estimatePriceImpact(%g, %g) = %g, want %g", tt.liquidity, tt.tradeSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlippageBucket(t *testing.T) {
+	tests := []struct {
+		impact float64
+		want   string
+	}{
+		{impact: 0, want: "Low (<0.5%)"},
+		{impact: 0.49, want: "Low (<0.5%)"},
+		{impact: 0.5, want: "Medium (0.5-2%)"},
+		{impact: 1.99, want: "Medium (0.5-2%)"},
+		{impact: 2, want: "High (2-5%)"},
+		{impact: 5, want: "Very High (>5%)"},
+		{impact: 100, want: "Very High (>5%)"},
+	}
+	for _, tt := range tests {
+		if got := slippageBucket(tt.impact); got != tt.want {
+			t.Errorf("slippageBucket(%g) = %q, want %q", tt.impact, got, tt.want)
+		}
+	}
+}
+
+func TestBuildDexLiquidityInfo(t *testing.T) {
+	pair := func(dex string, liquidity, volume float64) dto.GetLiquidityRequest {
+		return dto.GetLiquidityRequest{DexID: dex, Liquidity: dto.CurrencyValue{USD: liquidity}, Volume: dto.DexVolume{H24: volume}}
+	}
+
+	tests := []struct {
+		name      string
+		pairs     []dto.GetLiquidityRequest
+		marketCap float64
+		want      dto.DexLiquidityInfo
+		impacts   []float64
+	}{
+		{
+			name:    "no pools",
+			want:    dto.DexLiquidityInfo{SlippageNote: "Very High (>5%)"},
+			impacts: []float64{100, 100, 100},
+		},
+		{
+			name:      "liquidity summed per dex",
+			pairs:     []dto.GetLiquidityRequest{pair("raydium", 600_000, 10_000), pair("orca", 500_000, 5_000), pair("orca", 900_000, 1_000)},
+			marketCap: 20_000_000,
+			want: dto.DexLiquidityInfo{
+				LiquidityPoolSize: 2_000_000, PoolCount: 3, TopDex: "orca", TopDexLiquidity: 1_400_000,
+				Volume24h: 16_000, DexLiquidityRatio: 10, SlippageNote: "Low (<0.5%)",
+			},
+			impacts: []float64{0.0999, 0.9901, 9.0909},
+		},
+		{
+			name:  "ties go to the first dex by name without a market cap",
+			pairs: []dto.GetLiquidityRequest{pair("raydium", 10_000, 0), pair("meteora", 10_000, 0)},
+			want: dto.DexLiquidityInfo{
+				LiquidityPoolSize: 20_000, PoolCount: 2, TopDex: "meteora", TopDexLiquidity: 10_000,
+				SlippageNote: "Very High (>5%)",
+			},
+			impacts: []float64{9.0909, 50, 90.9091},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildDexLiquidityInfo(tt.pairs, tt.marketCap)
+			estimates := got.SlippageEstimates
+			got.SlippageEstimates = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildDexLiquidityInfo() = %+v, want %+v", got, tt.want)
+			}
+
+			if len(estimates) != len(slippageTradeSizes) {
+				t.Fatalf("got %d slippage estimates, want %d", len(estimates), len(slippageTradeSizes))
+			}
+			for i, estimate := range estimates {
+				if estimate.TradeSizeUSD != slippageTradeSizes[i] || estimate.PriceImpactPct != tt.impacts[i] {
+					t.Errorf("estimate %d = %+v, want $%g at %g%%", i, estimate, slippageTradeSizes[i], tt.impacts[i])
+				}
+			}
+		})
+	}
+}
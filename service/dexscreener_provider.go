@@ -0,0 +1,50 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	httprequest "blockchain-scrap/pkg/http-request"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+const defaultDexScreenerBaseURL = "https://api.dexscreener.com"
+
+// dexScreenerProvider implements DexLiquiditySource using the DexScreener API
+type dexScreenerProvider struct {
+	baseURL string
+}
+
+// NewDexScreenerProvider creates a DexLiquiditySource for DexScreener, falling back to the public API when baseURL is empty
+func NewDexScreenerProvider(baseURL string) DexLiquiditySource {
+	if baseURL == "" {
+		baseURL = defaultDexScreenerBaseURL
+	}
+	return &dexScreenerProvider{baseURL: baseURL}
+}
+
+// GetDexPairs fetches every DEX pair of one or more comma separated tokens on the given chain
+func (p *dexScreenerProvider) GetDexPairs(ctx context.Context, chainID, tokenAddress string) ([]dto.GetLiquidityRequest, errs.MessageErr) {
+	endpoint := p.baseURL + "/tokens/v1/" + url.PathEscape(chainID) + "/" + strings.Join(escapeEach(strings.Split(tokenAddress, ",")), ",")
+	body, err := httprequest.ProcessJSONRequestWithContext(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []dto.GetLiquidityRequest{}
+	if err := json.Unmarshal(body, &pairs); err != nil {
+		return nil, errs.NewInternalServerError("Failed to process liquidity data")
+	}
+	return pairs, nil
+}
+
+// escapeEach path-escapes every element of values
+func escapeEach(values []string) []string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = url.PathEscape(value)
+	}
+	return escaped
+}
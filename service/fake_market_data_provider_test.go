@@ -0,0 +1,84 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"strings"
+)
+
+// FakeMarketData is the fixed data served by the fake MarketDataProvider
+type FakeMarketData struct {
+	Coins   map[string]dto.ContractAddressResponse // by contract address
+	Charts  map[string]dto.GetPricesRequest        // by coin ID
+	Pairs   map[string][]dto.GetLiquidityRequest   // by token address
+	Markets []map[string]interface{}
+}
+
+// fakeMarketDataProvider serves FakeMarketData in process so the service can be tested offline
+type fakeMarketDataProvider struct {
+	data FakeMarketData
+}
+
+// NewFakeMarketDataProvider creates a MarketDataProvider that never leaves the process
+func NewFakeMarketDataProvider(data FakeMarketData) MarketDataProvider {
+	return &fakeMarketDataProvider{data: data}
+}
+
+// GetCoinByContract returns a copy of the coin of the contract address
+func (p *fakeMarketDataProvider) GetCoinByContract(ctx context.Context, platformID, contractAddress string) (*dto.ContractAddressResponse, errs.MessageErr) {
+	coin, ok := p.data.Coins[contractAddress]
+	if !ok {
+		return nil, errs.NewNotFound("Coin not found")
+	}
+	return &coin, nil
+}
+
+// GetMarketChart returns the market chart of the coin whatever the number of days
+func (p *fakeMarketDataProvider) GetMarketChart(ctx context.Context, coinID, days string) (*dto.GetPricesRequest, errs.MessageErr) {
+	chart, ok := p.data.Charts[coinID]
+	if !ok {
+		return nil, errs.NewNotFound("Coin not found")
+	}
+	return &chart, nil
+}
+
+func (p *fakeMarketDataProvider) GetMarkets(ctx context.Context) ([]map[string]interface{}, errs.MessageErr) {
+	return p.data.Markets, nil
+}
+
+// GetMarketsByIDs returns the markets whose "id" is one of coinIDs
+func (p *fakeMarketDataProvider) GetMarketsByIDs(ctx context.Context, coinIDs []string) ([]map[string]interface{}, errs.MessageErr) {
+	wanted := make(map[string]bool, len(coinIDs))
+	for _, id := range coinIDs {
+		wanted[id] = true
+	}
+
+	markets := make([]map[string]interface{}, 0, len(coinIDs))
+	for _, market := range p.data.Markets {
+		if id, _ := market["id"].(string); wanted[id] {
+			markets = append(markets, market)
+		}
+	}
+	return markets, nil
+}
+
+// GetTokenPrices prices the contract addresses with the current price of their coin
+func (p *fakeMarketDataProvider) GetTokenPrices(ctx context.Context, platformID string, contractAddresses []string) (map[string]dto.TokenPrice, errs.MessageErr) {
+	prices := make(map[string]dto.TokenPrice, len(contractAddresses))
+	for _, address := range contractAddresses {
+		if coin, ok := p.data.Coins[address]; ok {
+			prices[address] = dto.TokenPrice{PriceUSD: coin.MarketData.CurrentPrice.USD, Source: "fake"}
+		}
+	}
+	return prices, nil
+}
+
+// GetDexPairs returns the pairs of every comma separated token address
+func (p *fakeMarketDataProvider) GetDexPairs(ctx context.Context, chainID, tokenAddress string) ([]dto.GetLiquidityRequest, errs.MessageErr) {
+	pairs := []dto.GetLiquidityRequest{}
+	for _, address := range strings.Split(tokenAddress, ",") {
+		pairs = append(pairs, p.data.Pairs[strings.TrimSpace(address)]...)
+	}
+	return pairs, nil
+}
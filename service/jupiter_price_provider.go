@@ -0,0 +1,57 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	httprequest "blockchain-scrap/pkg/http-request"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const defaultJupiterPriceBaseURL = "https://api.jup.ag/price/v2"
+
+// jupiterPriceProvider implements TokenPriceSource using the Jupiter price API
+type jupiterPriceProvider struct {
+	baseURL string
+}
+
+// NewJupiterPriceProvider creates a TokenPriceSource for Jupiter, falling back to the public API when baseURL is empty
+func NewJupiterPriceProvider(baseURL string) TokenPriceSource {
+	if baseURL == "" {
+		baseURL = defaultJupiterPriceBaseURL
+	}
+	return &jupiterPriceProvider{baseURL: baseURL}
+}
+
+// GetTokenPrices fetches the USD price of the given mints, Jupiter does not report a 24h change
+func (p *jupiterPriceProvider) GetTokenPrices(ctx context.Context, mints []string) (map[string]dto.TokenPrice, errs.MessageErr) {
+	endpoint := p.baseURL + "?ids=" + strings.Join(escapeEach(mints), ",")
+	body, err := httprequest.ProcessJSONRequestWithContext(ctx, "GET", endpoint, nil, nil)
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch Jupiter prices")
+	}
+
+	var response struct {
+		Data map[string]*struct {
+			Price string `json:"price"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errs.NewInternalServerError("Failed to process Jupiter prices")
+	}
+
+	prices := make(map[string]dto.TokenPrice, len(response.Data))
+	for mint, entry := range response.Data {
+		if entry == nil {
+			continue
+		}
+		price, errParse := strconv.ParseFloat(entry.Price, 64)
+		if errParse != nil {
+			continue
+		}
+		prices[mint] = dto.TokenPrice{PriceUSD: price, Source: "jupiter"}
+	}
+	return prices, nil
+}
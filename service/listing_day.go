@@ -0,0 +1,52 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
+	"time"
+)
+
+// Listing date sources reported in ContractAddressResponse.ListingSource, in order of precedence
+const (
+	ListingSourceMintedAt       = "minted_at"
+	ListingSourceGenesisDate    = "genesis_date"
+	ListingSourceDexPairCreated = "dex_pair_created_at"
+)
+
+// resolveListingDay returns the number of whole days since the token was listed and the source used.
+//
+// The on-chain mint time stored on entity.Token is preferred, then the CoinGecko genesis date,
+// then the creation time of the oldest DexScreener pair. It returns nil and an empty source
+// when none of them is known.
+func resolveListingDay(token *entity.Token, genesisDate string, pairs []dto.GetLiquidityRequest, now time.Time) (*int, string) {
+	if token != nil && token.MintedAt != nil && !token.MintedAt.IsZero() {
+		return daysSince(*token.MintedAt, now), ListingSourceMintedAt
+	}
+
+	if genesisDate != "" {
+		if listedAt, err := time.Parse("2006-01-02", genesisDate); err == nil {
+			return daysSince(listedAt, now), ListingSourceGenesisDate
+		}
+	}
+
+	var oldestPair int64
+	for _, pair := range pairs {
+		if pair.PairCreatedAt > 0 && (oldestPair == 0 || pair.PairCreatedAt < oldestPair) {
+			oldestPair = pair.PairCreatedAt
+		}
+	}
+	if oldestPair > 0 {
+		return daysSince(time.UnixMilli(oldestPair), now), ListingSourceDexPairCreated
+	}
+
+	return nil, ""
+}
+
+// daysSince returns the number of whole days between listedAt and now, never negative
+func daysSince(listedAt, now time.Time) *int {
+	days := int(now.Sub(listedAt).Hours() / 24)
+	if days < 0 {
+		days = 0
+	}
+	return &days
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
+	"testing"
+	"time"
+)
+
+func TestResolveListingDay(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mintedAt := now.Add(-10 * 24 * time.Hour)
+	zero := time.Time{}
+	pairs := []dto.GetLiquidityRequest{
+		{PairCreatedAt: now.Add(-3 * 24 * time.Hour).UnixMilli()},
+		{PairCreatedAt: 0},
+		{PairCreatedAt: now.Add(-5*24*time.Hour - time.Hour).UnixMilli()},
+	}
+
+	tests := []struct {
+		name        string
+		token       *entity.Token
+		genesisDate string
+		pairs       []dto.GetLiquidityRequest
+		wantDays    *int
+		wantSource  string
+	}{
+		{name: "mint time first", token: &entity.Token{MintedAt: &mintedAt}, genesisDate: "2020-01-01", pairs: pairs, wantDays: intPtr(10), wantSource: ListingSourceMintedAt},
+		{name: "genesis date without mint time", token: &entity.Token{}, genesisDate: "2025-02-01", pairs: pairs, wantDays: intPtr(28), wantSource: ListingSourceGenesisDate},
+		{name: "zero mint time is unknown", token: &entity.Token{MintedAt: &zero}, genesisDate: "2025-02-01", wantDays: intPtr(28), wantSource: ListingSourceGenesisDate},
+		{name: "oldest pair without genesis date", genesisDate: "not a date", pairs: pairs, wantDays: intPtr(5), wantSource: ListingSourceDexPairCreated},
+		{name: "future dates clamp to zero", genesisDate: "2025-03-05", wantDays: intPtr(0), wantSource: ListingSourceGenesisDate},
+		{name: "nothing known", pairs: []dto.GetLiquidityRequest{{PairCreatedAt: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, source := resolveListingDay(tt.token, tt.genesisDate, tt.pairs, now)
+			if source != tt.wantSource {
+				t.Errorf("source = %q, want %q", source, tt.wantSource)
+			}
+			switch {
+			case tt.wantDays == nil && days != nil:
+				t.Errorf("days = %d, want nil", *days)
+			case tt.wantDays != nil && (days == nil || *days != *tt.wantDays):
+				t.Errorf("days = %v, want %d", days, *tt.wantDays)
+			}
+		})
+	}
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"blockchain-scrap/pkg/prompt"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PromptLocalSummary is the narrative template of the rule-based summary
+const PromptLocalSummary = "local_summary"
+
+// Summary sources selectable per request
+const (
+	SummarySourceAI    = "ai"    // the configured SummaryGenerator backend
+	SummarySourceLocal = "local" // the rule-based summary, no external call
+)
+
+// Thresholds of the rule-based summary
+const (
+	trendFlatPct           = 2.0    // price change within ±2% over the period is flat
+	volatilityLowPct       = 5.0    // price range under 5% of the average price is low volatility
+	volatilityHighPct      = 15.0   // price range over 15% of the average price is high volatility
+	liquidityThinUSD       = 50_000 // pools under $50k are thin whatever the market cap
+	liquidityThinRatio     = 1.0    // pools under 1% of market cap are thin
+	liquidityHealthyRatio  = 5.0    // pools over 5% of market cap are healthy
+	dilutionLowPct         = 90.0   // over 90% of the supply circulating is low dilution
+	dilutionHighPct        = 50.0   // under 50% of the supply circulating is high dilution
+	localSummaryRiskLimit  = 3      // notable risks mentioned at most
+	localSummaryHolderRisk = 50.0   // largest wallet share flagged when there is no risk assessment
+)
+
+// localSummaryFacts are the values the local summary template reads. Empty levels are unknown.
+type localSummaryFacts struct {
+	Name              string
+	Symbol            string
+	Price             float64
+	Trend             string // "up", "down" or "flat"
+	ChangePct         float64
+	Volatility        string // "low", "moderate" or "high"
+	VolatilityPct     float64
+	Liquidity         string // "none", "thin", "moderate" or "healthy"
+	LiquidityUSD      float64
+	LiquidityRatioPct float64
+	Dilution          string // "low", "moderate" or "high"
+	CirculatingPct    float64
+	Risks             []string
+	RiskLevel         string
+}
+
+// localSummaryGenerator writes a deterministic summary from the lookup with the local_summary template
+type localSummaryGenerator struct {
+	prompts *prompt.Templates
+}
+
+// NewLocalSummaryGenerator creates the rule-based SummaryGenerator
+func NewLocalSummaryGenerator(prompts *prompt.Templates) SummaryGenerator {
+	return &localSummaryGenerator{prompts: prompts}
+}
+
+func (g *localSummaryGenerator) Summarize(ctx context.Context, req SummaryRequest) (string, errs.MessageErr) {
+	text, err := g.prompts.Render(PromptLocalSummary, req.Language, buildLocalSummaryFacts(req.Data, req.Language))
+	if err != nil {
+		return "", errs.NewInternalServerError("Failed to build summary: " + err.Error())
+	}
+	return text, nil
+}
+
+// buildLocalSummaryFacts derives trend, volatility, liquidity health, dilution and risks from a lookup
+func buildLocalSummaryFacts(data *dto.ContractAddressResponse, language string) localSummaryFacts {
+	market := data.MarketData
+	facts := localSummaryFacts{
+		Name:   data.ID,
+		Symbol: strings.ToUpper(data.Symbol),
+		Price:  market.CurrentPrice.USD,
+	}
+
+	if prices := data.TimePrices; len(prices) >= 2 {
+		first, last := prices[0].Price, prices[len(prices)-1].Price
+		if facts.Price == 0 {
+			facts.Price = last
+		}
+		if first > 0 {
+			facts.ChangePct = (last - first) / first * 100
+			switch {
+			case facts.ChangePct > trendFlatPct:
+				facts.Trend = "up"
+			case facts.ChangePct < -trendFlatPct:
+				facts.Trend = "down"
+			default:
+				facts.Trend = "flat"
+			}
+		}
+
+		low, high, sum := math.Inf(1), math.Inf(-1), 0.0
+		for _, point := range prices {
+			low = math.Min(low, point.Price)
+			high = math.Max(high, point.Price)
+			sum += point.Price
+		}
+		if average := sum / float64(len(prices)); average > 0 {
+			facts.VolatilityPct = (high - low) / average * 100
+			switch {
+			case facts.VolatilityPct < volatilityLowPct:
+				facts.Volatility = "low"
+			case facts.VolatilityPct > volatilityHighPct:
+				facts.Volatility = "high"
+			default:
+				facts.Volatility = "moderate"
+			}
+		}
+	}
+
+	liquidity := data.LiquidityInfo
+	facts.LiquidityUSD = liquidity.LiquidityPoolSize
+	facts.LiquidityRatioPct = liquidity.DexLiquidityRatio
+	switch {
+	case liquidity.PoolCount == 0 && liquidity.LiquidityPoolSize == 0:
+		facts.Liquidity = "none"
+	case liquidity.LiquidityPoolSize < liquidityThinUSD, liquidity.DexLiquidityRatio > 0 && liquidity.DexLiquidityRatio < liquidityThinRatio:
+		facts.Liquidity = "thin"
+	case liquidity.DexLiquidityRatio > 0 && liquidity.DexLiquidityRatio < liquidityHealthyRatio:
+		facts.Liquidity = "moderate"
+	default:
+		facts.Liquidity = "healthy"
+	}
+
+	supply := market.MaxSupply
+	if supply <= 0 {
+		supply = market.TotalSupply
+	}
+	if supply > 0 && market.CirculatingSupply > 0 {
+		facts.CirculatingPct = math.Min(market.CirculatingSupply/supply*100, 100)
+		switch {
+		case facts.CirculatingPct >= dilutionLowPct:
+			facts.Dilution = "low"
+		case facts.CirculatingPct < dilutionHighPct:
+			facts.Dilution = "high"
+		default:
+			facts.Dilution = "moderate"
+		}
+	}
+
+	facts.Risks, facts.RiskLevel = notableRisks(data, language)
+	return facts
+}
+
+// localRiskPhrases translates risk flags for the local summary, languages missing here use the
+// flag's English description
+var localRiskPhrases = map[string]map[string]string{
+	"id": {
+		"mint_authority":       "mint authority masih aktif sehingga suplai baru dapat dicetak",
+		"permanent_delegate":   "permanent delegate dapat memindahkan atau membakar token milik pemegang",
+		"freeze_authority":     "freeze authority masih aktif sehingga akun pemegang dapat dibekukan",
+		"no_liquidity":         "tidak ada pool likuiditas DEX",
+		"low_liquidity":        "likuiditas DEX rendah",
+		"holder_concentration": "dompet terbesar memegang porsi suplai yang besar",
+		"top10_concentration":  "sepuluh dompet terbesar memegang sebagian besar suplai",
+		"unknown_holders":      "sebaran pemegang token tidak dapat dianalisis",
+		"new_token":            "token baru saja dicetak",
+		"low_volume":           "volume harian rendah",
+		"dev_sold":             "dompet pembuat sudah menjual token",
+		"sniper_holdings":      "dompet sniper masih memegang porsi suplai yang besar",
+	},
+}
+
+// notableRisks returns the highest scoring risk flags of a lookup, falling back to the
+// holder concentration when the lookup has no risk assessment
+func notableRisks(data *dto.ContractAddressResponse, language string) ([]string, string) {
+	phrases := localRiskPhrases[strings.ToLower(language)]
+	if data.Risk == nil {
+		if data.TokenAnalytics.TopHolder > localSummaryHolderRisk {
+			if phrase, ok := phrases["holder_concentration"]; ok {
+				return []string{phrase}, ""
+			}
+			return []string{"the largest wallet holds over half of the supply"}, ""
+		}
+		return nil, ""
+	}
+
+	flags := make([]dto.RiskFlag, 0, len(data.Risk.Flags))
+	for _, flag := range data.Risk.Flags {
+		if flag.Severity != riskInfo {
+			flags = append(flags, flag)
+		}
+	}
+	sort.SliceStable(flags, func(i, j int) bool { return flags[i].Points > flags[j].Points })
+	if len(flags) > localSummaryRiskLimit {
+		flags = flags[:localSummaryRiskLimit]
+	}
+
+	risks := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		if phrase, ok := phrases[flag.Code]; ok {
+			risks = append(risks, phrase)
+		} else {
+			risks = append(risks, lowerFirst(flag.Description))
+		}
+	}
+	return risks, data.Risk.Level
+}
+
+// lowerFirst lowercases the first letter of a sentence so it can be embedded in another one,
+// leaving acronyms such as DEX untouched
+func lowerFirst(sentence string) string {
+	if sentence == "" || (len(sentence) > 1 && unicode.IsUpper(rune(sentence[1]))) {
+		return sentence
+	}
+	return strings.ToLower(sentence[:1]) + sentence[1:]
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func pricePoints(prices ...float64) []dto.PricePoint {
+	points := make([]dto.PricePoint, len(prices))
+	for i, price := range prices {
+		points[i] = dto.PricePoint{Price: price}
+	}
+	return points
+}
+
+func TestBuildLocalSummaryFacts(t *testing.T) {
+	tests := []struct {
+		name           string
+		modify         func(data *dto.ContractAddressResponse)
+		wantTrend      string
+		wantChange     float64
+		wantVolatility string
+		wantLiquidity  string
+		wantDilution   string
+		wantCirculated float64
+	}{
+		{
+			name:          "no prices or supply",
+			modify:        func(data *dto.ContractAddressResponse) {},
+			wantLiquidity: "none",
+		},
+		{
+			name: "rising with moderate volatility",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.TimePrices = pricePoints(100, 110)
+			},
+			wantTrend:      "up",
+			wantChange:     10,
+			wantVolatility: "moderate",
+			wantLiquidity:  "none",
+		},
+		{
+			name: "flat with low volatility",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.TimePrices = pricePoints(100, 101)
+			},
+			wantTrend:      "flat",
+			wantChange:     1,
+			wantVolatility: "low",
+			wantLiquidity:  "none",
+		},
+		{
+			name: "falling with high volatility",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.TimePrices = pricePoints(100, 80)
+			},
+			wantTrend:      "down",
+			wantChange:     -20,
+			wantVolatility: "high",
+			wantLiquidity:  "none",
+		},
+		{
+			name: "thin pools under $50k",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.LiquidityInfo = dto.DexLiquidityInfo{PoolCount: 1, LiquidityPoolSize: 20_000, DexLiquidityRatio: 10}
+			},
+			wantLiquidity: "thin",
+		},
+		{
+			name: "thin pools relative to market cap",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.LiquidityInfo = dto.DexLiquidityInfo{PoolCount: 1, LiquidityPoolSize: 100_000, DexLiquidityRatio: 0.5}
+			},
+			wantLiquidity: "thin",
+		},
+		{
+			name: "moderate pools",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.LiquidityInfo = dto.DexLiquidityInfo{PoolCount: 1, LiquidityPoolSize: 100_000, DexLiquidityRatio: 3}
+			},
+			wantLiquidity: "moderate",
+		},
+		{
+			name: "healthy pools without market cap",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.LiquidityInfo = dto.DexLiquidityInfo{PoolCount: 1, LiquidityPoolSize: 100_000}
+			},
+			wantLiquidity: "healthy",
+		},
+		{
+			name: "low dilution from total supply",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.MarketData.TotalSupply = 100
+				data.MarketData.CirculatingSupply = 95
+			},
+			wantLiquidity:  "none",
+			wantDilution:   "low",
+			wantCirculated: 95,
+		},
+		{
+			name: "high dilution from max supply",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.MarketData.MaxSupply = 100
+				data.MarketData.TotalSupply = 50
+				data.MarketData.CirculatingSupply = 40
+			},
+			wantLiquidity:  "none",
+			wantDilution:   "high",
+			wantCirculated: 40,
+		},
+		{
+			name: "moderate dilution",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.MarketData.MaxSupply = 100
+				data.MarketData.CirculatingSupply = 70
+			},
+			wantLiquidity:  "none",
+			wantDilution:   "moderate",
+			wantCirculated: 70,
+		},
+		{
+			name: "circulating share capped at 100%",
+			modify: func(data *dto.ContractAddressResponse) {
+				data.MarketData.MaxSupply = 100
+				data.MarketData.CirculatingSupply = 200
+			},
+			wantLiquidity:  "none",
+			wantDilution:   "low",
+			wantCirculated: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &dto.ContractAddressResponse{ID: "dai", Symbol: "dai"}
+			tt.modify(data)
+
+			facts := buildLocalSummaryFacts(data, "en")
+
+			if facts.Symbol != "DAI" {
+				t.Errorf("Symbol = %q, want DAI", facts.Symbol)
+			}
+			if facts.Trend != tt.wantTrend {
+				t.Errorf("Trend = %q, want %q", facts.Trend, tt.wantTrend)
+			}
+			if math.Abs(facts.ChangePct-tt.wantChange) > 1e-9 {
+				t.Errorf("ChangePct = %g, want %g", facts.ChangePct, tt.wantChange)
+			}
+			if facts.Volatility != tt.wantVolatility {
+				t.Errorf("Volatility = %q, want %q", facts.Volatility, tt.wantVolatility)
+			}
+			if facts.Liquidity != tt.wantLiquidity {
+				t.Errorf("Liquidity = %q, want %q", facts.Liquidity, tt.wantLiquidity)
+			}
+			if facts.Dilution != tt.wantDilution {
+				t.Errorf("Dilution = %q, want %q", facts.Dilution, tt.wantDilution)
+			}
+			if math.Abs(facts.CirculatingPct-tt.wantCirculated) > 1e-9 {
+				t.Errorf("CirculatingPct = %g, want %g", facts.CirculatingPct, tt.wantCirculated)
+			}
+		})
+	}
+}
+
+func TestBuildLocalSummaryFactsPriceFallback(t *testing.T) {
+	data := &dto.ContractAddressResponse{TimePrices: pricePoints(1, 2)}
+
+	if facts := buildLocalSummaryFacts(data, "en"); facts.Price != 2 {
+		t.Errorf("Price = %g, want the last chart price 2", facts.Price)
+	}
+}
+
+func TestNotableRisks(t *testing.T) {
+	risk := &dto.TokenRisk{
+		Level: RiskLevelHigh,
+		Flags: []dto.RiskFlag{
+			{Code: "unknown_age", Severity: riskInfo, Points: 5, Description: "The mint date of the token is unknown"},
+			{Code: "low_volume", Severity: riskWarning, Points: 10, Description: "Daily volume is only $500"},
+			{Code: "mint_authority", Severity: riskDanger, Points: 25, Description: "Mint authority is still set"},
+			{Code: "no_liquidity", Severity: riskDanger, Points: 25, Description: "No DEX liquidity pool was found"},
+			{Code: "new_token", Severity: riskDanger, Points: 15, Description: "The token was minted less than a day ago"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		data      *dto.ContractAddressResponse
+		language  string
+		wantRisks []string
+		wantLevel string
+	}{
+		{
+			name:      "top scoring flags without info flags",
+			data:      &dto.ContractAddressResponse{Risk: risk},
+			language:  "en",
+			wantRisks: []string{"mint authority is still set", "no DEX liquidity pool was found", "the token was minted less than a day ago"},
+			wantLevel: RiskLevelHigh,
+		},
+		{
+			name:     "translated flags",
+			data:     &dto.ContractAddressResponse{Risk: risk},
+			language: "ID",
+			wantRisks: []string{
+				"mint authority masih aktif sehingga suplai baru dapat dicetak",
+				"tidak ada pool likuiditas DEX",
+				"token baru saja dicetak",
+			},
+			wantLevel: RiskLevelHigh,
+		},
+		{
+			name:      "holder concentration without risk assessment",
+			data:      &dto.ContractAddressResponse{TokenAnalytics: dto.TokenAnalytics{TopHolder: 60}},
+			language:  "en",
+			wantRisks: []string{"the largest wallet holds over half of the supply"},
+		},
+		{
+			name:      "translated holder concentration without risk assessment",
+			data:      &dto.ContractAddressResponse{TokenAnalytics: dto.TokenAnalytics{TopHolder: 60}},
+			language:  "id",
+			wantRisks: []string{"dompet terbesar memegang porsi suplai yang besar"},
+		},
+		{
+			name:     "nothing notable",
+			data:     &dto.ContractAddressResponse{TokenAnalytics: dto.TokenAnalytics{TopHolder: 10}},
+			language: "en",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risks, level := notableRisks(tt.data, tt.language)
+			if !reflect.DeepEqual(risks, tt.wantRisks) {
+				t.Errorf("risks = %q, want %q", risks, tt.wantRisks)
+			}
+			if level != tt.wantLevel {
+				t.Errorf("level = %q, want %q", level, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestLowerFirst(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"A", "a"},
+		{"Daily volume is low", "daily volume is low"},
+		{"DEX liquidity is only $5", "DEX liquidity is only $5"},
+		{"already lower", "already lower"},
+	}
+
+	for _, tt := range tests {
+		if got := lowerFirst(tt.in); got != tt.want {
+			t.Errorf("lowerFirst(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"context"
+)
+
+// CoinMarketSource defines the contract for coin metadata and market price providers
+type CoinMarketSource interface {
+	GetCoinByContract(ctx context.Context, platformID, contractAddress string) (*dto.ContractAddressResponse, errs.MessageErr)
+	GetMarketChart(ctx context.Context, coinID, days string) (*dto.GetPricesRequest, errs.MessageErr)
+	GetMarkets(ctx context.Context) ([]map[string]interface{}, errs.MessageErr)
+	GetMarketsByIDs(ctx context.Context, coinIDs []string) ([]map[string]interface{}, errs.MessageErr)
+	GetTokenPrices(ctx context.Context, platformID string, contractAddresses []string) (map[string]dto.TokenPrice, errs.MessageErr)
+}
+
+// DexLiquiditySource defines the contract for DEX pair and liquidity providers.
+// GetDexPairs accepts several token addresses separated by commas.
+type DexLiquiditySource interface {
+	GetDexPairs(ctx context.Context, chainID, tokenAddress string) ([]dto.GetLiquidityRequest, errs.MessageErr)
+}
+
+// TokenPriceSource defines the contract for USD price providers of Solana mints.
+// Mints the source cannot price are left out of the result.
+type TokenPriceSource interface {
+	GetTokenPrices(ctx context.Context, mints []string) (map[string]dto.TokenPrice, errs.MessageErr)
+}
+
+// MarketDataProvider combines every upstream market data call used by BlockchainService
+type MarketDataProvider interface {
+	CoinMarketSource
+	DexLiquiditySource
+}
+
+// marketDataProvider composes independent coin and DEX sources into a MarketDataProvider
+type marketDataProvider struct {
+	CoinMarketSource
+	DexLiquiditySource
+}
+
+// NewMarketDataProvider creates a MarketDataProvider backed by the given coin and DEX sources
+func NewMarketDataProvider(coins CoinMarketSource, dex DexLiquiditySource) MarketDataProvider {
+	return &marketDataProvider{CoinMarketSource: coins, DexLiquiditySource: dex}
+}
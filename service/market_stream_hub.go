@@ -0,0 +1,221 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Market stream event types
+const (
+	MarketEventSnapshot = "snapshot"
+	MarketEventDelta    = "delta"
+	MarketEventError    = "error"
+)
+
+// subscriberBuffer is the number of events a subscriber may lag behind before it is dropped
+const subscriberBuffer = 16
+
+// MarketEvent is a single event of the market stream. Error events carry no ID.
+type MarketEvent struct {
+	ID   uint64
+	Type string
+	Data interface{}
+}
+
+// MarketStreamHub polls the market list once for every subscriber and fans out the changes
+type MarketStreamHub interface {
+	Run(ctx context.Context)
+	Subscribe(lastEventID uint64) ([]MarketEvent, <-chan MarketEvent, func())
+}
+
+// marketStreamHub implements MarketStreamHub
+type marketStreamHub struct {
+	blockchainSvc BlockchainService
+	interval      time.Duration
+	historySize   int
+	wake          chan struct{}
+
+	mu          sync.Mutex
+	lastID      uint64
+	coins       map[string]json.RawMessage
+	snapshot    []map[string]interface{}
+	history     []MarketEvent
+	subscribers map[chan MarketEvent]struct{}
+}
+
+// NewMarketStreamHub creates a MarketStreamHub polling every interval and keeping historySize delta events for resumption
+func NewMarketStreamHub(blockchainSvc BlockchainService, interval time.Duration, historySize int) MarketStreamHub {
+	return &marketStreamHub{
+		blockchainSvc: blockchainSvc,
+		interval:      interval,
+		historySize:   historySize,
+		wake:          make(chan struct{}, 1),
+		coins:         make(map[string]json.RawMessage),
+		subscribers:   make(map[chan MarketEvent]struct{}),
+	}
+}
+
+// Run polls the market list until ctx is done. Polling pauses while nobody is subscribed.
+func (h *marketStreamHub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.wake:
+		}
+
+		if h.subscriberCount() > 0 {
+			h.poll(ctx)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. It returns the events to send first and a channel of live events.
+// A subscriber resuming from a lastEventID still in the history receives the deltas it missed,
+// anyone else receives the current snapshot. The channel is closed when the subscriber falls too
+// far behind; it should reconnect with its last event ID.
+func (h *marketStreamHub) Subscribe(lastEventID uint64) ([]MarketEvent, <-chan MarketEvent, func()) {
+	ch := make(chan MarketEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	initial := h.replay(lastEventID)
+	hasSnapshot := h.snapshot != nil
+	h.mu.Unlock()
+
+	if !hasSnapshot {
+		select {
+		case h.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return initial, ch, unsubscribe
+}
+
+// replay returns the events a subscriber needs to catch up from lastEventID; h.mu must be held
+func (h *marketStreamHub) replay(lastEventID uint64) []MarketEvent {
+	if h.snapshot == nil {
+		return nil
+	}
+
+	if lastEventID > 0 && lastEventID <= h.lastID {
+		if lastEventID == h.lastID {
+			return nil
+		}
+		if len(h.history) > 0 && h.history[0].ID <= lastEventID+1 {
+			missed := []MarketEvent{}
+			for _, event := range h.history {
+				if event.ID > lastEventID {
+					missed = append(missed, event)
+				}
+			}
+			return missed
+		}
+	}
+
+	return []MarketEvent{{ID: h.lastID, Type: MarketEventSnapshot, Data: h.snapshot}}
+}
+
+// poll fetches the market list once and broadcasts what changed since the previous poll
+func (h *marketStreamHub) poll(ctx context.Context) {
+	coins, err := h.blockchainSvc.GetAllBlockchains(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		h.broadcast(MarketEvent{Type: MarketEventError, Data: map[string]string{"error": err.Message()}})
+		return
+	}
+
+	first := h.snapshot == nil
+	delta, current := diffMarkets(h.coins, coins)
+	h.coins = current
+	h.snapshot = coins
+
+	if first {
+		h.lastID++
+		h.broadcast(MarketEvent{ID: h.lastID, Type: MarketEventSnapshot, Data: coins})
+		return
+	}
+
+	if len(delta.Updated) == 0 && len(delta.Removed) == 0 {
+		return
+	}
+
+	h.lastID++
+	event := MarketEvent{ID: h.lastID, Type: MarketEventDelta, Data: delta}
+	h.history = append(h.history, event)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+	h.broadcast(event)
+}
+
+// broadcast sends an event to every subscriber, dropping the ones whose buffer is full; h.mu must be held
+func (h *marketStreamHub) broadcast(event MarketEvent) {
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Println("Dropping slow market stream subscriber")
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscriberCount returns the number of active subscribers
+func (h *marketStreamHub) subscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// diffMarkets compares the latest market list to the previous one keyed by coin ID,
+// returning the changed and removed coins and the encoded state of the latest list
+func diffMarkets(previous map[string]json.RawMessage, coins []map[string]interface{}) (dto.MarketDelta, map[string]json.RawMessage) {
+	delta := dto.MarketDelta{Updated: []map[string]interface{}{}, Removed: []string{}}
+	current := make(map[string]json.RawMessage, len(coins))
+
+	for _, coin := range coins {
+		id, _ := coin["id"].(string)
+		if id == "" {
+			continue
+		}
+		encoded, err := json.Marshal(coin)
+		if err != nil {
+			continue
+		}
+		current[id] = encoded
+		if old, ok := previous[id]; !ok || string(old) != string(encoded) {
+			delta.Updated = append(delta.Updated, coin)
+		}
+	}
+
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+
+	return delta, current
+}
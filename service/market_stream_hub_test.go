@@ -0,0 +1,232 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// stubMarketList returns one market list per GetAllBlockchains call
+type stubMarketList struct {
+	BlockchainService
+	lists [][]map[string]interface{}
+	calls int
+}
+
+func (s *stubMarketList) GetAllBlockchains(ctx context.Context) ([]map[string]interface{}, errs.MessageErr) {
+	list := s.lists[s.calls]
+	s.calls++
+	return list, nil
+}
+
+func coin(id string, price float64) map[string]interface{} {
+	return map[string]interface{}{"id": id, "current_price": price}
+}
+
+func TestDiffMarkets(t *testing.T) {
+	_, previous := diffMarkets(nil, []map[string]interface{}{coin("bitcoin", 100), coin("ethereum", 10)})
+
+	tests := []struct {
+		name        string
+		coins       []map[string]interface{}
+		wantUpdated []string
+		wantRemoved []string
+	}{
+		{
+			name:        "unchanged",
+			coins:       []map[string]interface{}{coin("bitcoin", 100), coin("ethereum", 10)},
+			wantUpdated: []string{},
+			wantRemoved: []string{},
+		},
+		{
+			name:        "price changed",
+			coins:       []map[string]interface{}{coin("bitcoin", 101), coin("ethereum", 10)},
+			wantUpdated: []string{"bitcoin"},
+			wantRemoved: []string{},
+		},
+		{
+			name:        "coin added and removed",
+			coins:       []map[string]interface{}{coin("bitcoin", 100), coin("solana", 1)},
+			wantUpdated: []string{"solana"},
+			wantRemoved: []string{"ethereum"},
+		},
+		{
+			name:        "coins without id are ignored",
+			coins:       []map[string]interface{}{coin("bitcoin", 100), coin("ethereum", 10), {"current_price": 5.0}},
+			wantUpdated: []string{},
+			wantRemoved: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, current := diffMarkets(previous, tt.coins)
+
+			updated := []string{}
+			for _, coin := range delta.Updated {
+				updated = append(updated, coin["id"].(string))
+			}
+			if !reflect.DeepEqual(updated, tt.wantUpdated) {
+				t.Errorf("Updated = %v, want %v", updated, tt.wantUpdated)
+			}
+			sort.Strings(delta.Removed)
+			if !reflect.DeepEqual(delta.Removed, tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", delta.Removed, tt.wantRemoved)
+			}
+			if len(current) != len(tt.coins)-countWithoutID(tt.coins) {
+				t.Errorf("len(current) = %d, want one entry per coin with an id", len(current))
+			}
+		})
+	}
+}
+
+func countWithoutID(coins []map[string]interface{}) int {
+	n := 0
+	for _, coin := range coins {
+		if _, ok := coin["id"]; !ok {
+			n++
+		}
+	}
+	return n
+}
+
+// newPolledHub returns a hub that polled: a snapshot (event 1), a price change (event 2),
+// an unchanged list (no event) and a removed coin (event 3)
+func newPolledHub(t *testing.T, historySize int) *marketStreamHub {
+	t.Helper()
+
+	svc := &stubMarketList{lists: [][]map[string]interface{}{
+		{coin("bitcoin", 100), coin("ethereum", 10)},
+		{coin("bitcoin", 101), coin("ethereum", 10)},
+		{coin("bitcoin", 101), coin("ethereum", 10)},
+		{coin("bitcoin", 101)},
+	}}
+	hub := NewMarketStreamHub(svc, 0, historySize).(*marketStreamHub)
+	for range svc.lists {
+		hub.poll(context.Background())
+	}
+	if hub.lastID != 3 {
+		t.Fatalf("lastID = %d, want 3", hub.lastID)
+	}
+	return hub
+}
+
+func TestMarketStreamHubLiveEvents(t *testing.T) {
+	svc := &stubMarketList{lists: [][]map[string]interface{}{
+		{coin("bitcoin", 100)},
+		{coin("bitcoin", 100)},
+		{coin("bitcoin", 101)},
+	}}
+	hub := NewMarketStreamHub(svc, 0, 8).(*marketStreamHub)
+
+	initial, events, unsubscribe := hub.Subscribe(0)
+	defer unsubscribe()
+	if len(initial) != 0 {
+		t.Fatalf("initial = %v, want nothing before the first poll", initial)
+	}
+
+	for range svc.lists {
+		hub.poll(context.Background())
+	}
+
+	want := []struct {
+		id        uint64
+		eventType string
+	}{
+		{1, MarketEventSnapshot},
+		{2, MarketEventDelta},
+	}
+	for _, w := range want {
+		event := <-events
+		if event.ID != w.id || event.Type != w.eventType {
+			t.Errorf("event = %d %s, want %d %s", event.ID, event.Type, w.id, w.eventType)
+		}
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %d %s for an unchanged list", event.ID, event.Type)
+	default:
+	}
+}
+
+func TestMarketStreamHubResume(t *testing.T) {
+	tests := []struct {
+		name        string
+		historySize int
+		lastEventID uint64
+		wantIDs     []uint64
+		wantTypes   []string
+	}{
+		{name: "new subscriber", historySize: 8, lastEventID: 0, wantIDs: []uint64{3}, wantTypes: []string{MarketEventSnapshot}},
+		{name: "resume from snapshot", historySize: 8, lastEventID: 1, wantIDs: []uint64{2, 3}, wantTypes: []string{MarketEventDelta, MarketEventDelta}},
+		{name: "resume from a delta", historySize: 8, lastEventID: 2, wantIDs: []uint64{3}, wantTypes: []string{MarketEventDelta}},
+		{name: "up to date", historySize: 8, lastEventID: 3},
+		{name: "unknown future event", historySize: 8, lastEventID: 9, wantIDs: []uint64{3}, wantTypes: []string{MarketEventSnapshot}},
+		{name: "missed deltas evicted from history", historySize: 1, lastEventID: 1, wantIDs: []uint64{3}, wantTypes: []string{MarketEventSnapshot}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := newPolledHub(t, tt.historySize)
+
+			initial, _, unsubscribe := hub.Subscribe(tt.lastEventID)
+			defer unsubscribe()
+
+			var ids []uint64
+			var types []string
+			for _, event := range initial {
+				ids = append(ids, event.ID)
+				types = append(types, event.Type)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || !reflect.DeepEqual(types, tt.wantTypes) {
+				t.Errorf("initial = %v %v, want %v %v", ids, types, tt.wantIDs, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestMarketStreamHubResumeDelta(t *testing.T) {
+	hub := newPolledHub(t, 8)
+
+	initial, _, unsubscribe := hub.Subscribe(2)
+	defer unsubscribe()
+
+	if len(initial) != 1 {
+		t.Fatalf("len(initial) = %d, want 1", len(initial))
+	}
+	delta, ok := initial[0].Data.(dto.MarketDelta)
+	if !ok {
+		t.Fatalf("Data = %T, want dto.MarketDelta", initial[0].Data)
+	}
+	if len(delta.Updated) != 0 || !reflect.DeepEqual(delta.Removed, []string{"ethereum"}) {
+		t.Errorf("delta = %+v, want ethereum removed", delta)
+	}
+}
+
+func TestMarketStreamHubDropsSlowSubscriber(t *testing.T) {
+	svc := &stubMarketList{}
+	for i := 0; i <= subscriberBuffer; i++ {
+		svc.lists = append(svc.lists, []map[string]interface{}{coin("bitcoin", float64(i))})
+	}
+	hub := NewMarketStreamHub(svc, 0, 8).(*marketStreamHub)
+
+	_, events, unsubscribe := hub.Subscribe(0)
+	defer unsubscribe()
+	for range svc.lists {
+		hub.poll(context.Background())
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("received %d events before the channel closed, want %d", received, subscriberBuffer)
+	}
+	if hub.subscriberCount() != 0 {
+		t.Errorf("subscriberCount = %d, want 0", hub.subscriberCount())
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// tokenPriceBatchSize is the number of mints priced per upstream request
+	tokenPriceBatchSize = 30
+	// lamportDecimals converts the native SOL balance, reported in lamports
+	lamportDecimals = 9
+)
+
+// GetPortfolio values every holding of a wallet in USD. Holdings are priced from CoinGecko first,
+// mints CoinGecko does not list are priced from Jupiter, and holdings neither can price are
+// returned with a zero value.
+func (s *tokenService) GetPortfolio(ctx context.Context, address string) (*dto.PortfolioResponse, errs.MessageErr) {
+	accounts, err := s.FetchAccountInfo(address)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := make([]dto.PortfolioHolding, 0, len(accounts))
+	mints := []string{}
+	seen := make(map[string]bool)
+	for _, account := range accounts {
+		decimals := account.Decimals
+		if account.Program == "native" {
+			decimals = lamportDecimals
+		}
+		raw, errParse := strconv.ParseFloat(account.Amount, 64)
+		if errParse != nil || raw <= 0 {
+			continue
+		}
+		holdings = append(holdings, dto.PortfolioHolding{
+			Address:   account.Address,
+			Symbol:    account.Symbol,
+			Name:      account.Name,
+			LogoURI:   account.LogoURI,
+			Program:   account.Program,
+			Decimals:  decimals,
+			RawAmount: account.Amount,
+			Amount:    raw / math.Pow10(decimals),
+		})
+		if !seen[account.Address] {
+			seen[account.Address] = true
+			mints = append(mints, account.Address)
+		}
+	}
+
+	prices, errPrices := s.tokenPrices(ctx, mints)
+	if errPrices != nil {
+		return nil, errPrices
+	}
+
+	response := &dto.PortfolioResponse{Wallet: address, PricedAt: time.Now()}
+	var previousTotal float64
+	for i := range holdings {
+		holding := &holdings[i]
+		price, ok := prices[holding.Address]
+		if !ok {
+			response.UnpricedTokens++
+			continue
+		}
+		priceUSD := price.PriceUSD
+		holding.PriceUSD = &priceUSD
+		holding.PriceSource = price.Source
+		holding.Change24h = price.Change24h
+		holding.ValueUSD = holding.Amount * priceUSD
+		response.TotalValueUSD += holding.ValueUSD
+
+		previousValue := holding.ValueUSD
+		if price.Change24h != nil && *price.Change24h > -100 {
+			previousValue = holding.ValueUSD / (1 + *price.Change24h/100)
+		}
+		previousTotal += previousValue
+	}
+
+	for i := range holdings {
+		if response.TotalValueUSD > 0 {
+			holdings[i].AllocationPct = roundTo(holdings[i].ValueUSD/response.TotalValueUSD*100, 4)
+		}
+	}
+	sort.SliceStable(holdings, func(i, j int) bool { return holdings[i].ValueUSD > holdings[j].ValueUSD })
+
+	response.Change24hUSD = response.TotalValueUSD - previousTotal
+	if previousTotal > 0 {
+		change := roundTo(response.Change24hUSD/previousTotal*100, 4)
+		response.Change24hPct = &change
+	}
+	response.Holdings = holdings
+	return response, nil
+}
+
+// tokenPrices prices mints from CoinGecko and falls back to Jupiter for the ones it is missing.
+// It only fails when there is something to price and every source failed.
+func (s *tokenService) tokenPrices(ctx context.Context, mints []string) (map[string]dto.TokenPrice, errs.MessageErr) {
+	prices := make(map[string]dto.TokenPrice, len(mints))
+	var lastErr errs.MessageErr
+	succeeded := false
+
+	for _, batch := range batchStrings(mints, tokenPriceBatchSize) {
+		batchPrices, err := s.coins.GetTokenPrices(ctx, solanaPlatformID, batch)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		succeeded = true
+		for mint, price := range batchPrices {
+			prices[mint] = price
+		}
+	}
+
+	missing := []string{}
+	for _, mint := range mints {
+		if _, ok := prices[mint]; !ok {
+			missing = append(missing, mint)
+		}
+	}
+	for _, batch := range batchStrings(missing, tokenPriceBatchSize) {
+		batchPrices, err := s.prices.GetTokenPrices(ctx, batch)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		succeeded = true
+		for mint, price := range batchPrices {
+			prices[mint] = price
+		}
+	}
+
+	if !succeeded && lastErr != nil {
+		return nil, lastErr
+	}
+	return prices, nil
+}
+
+// batchStrings splits values into consecutive batches of at most size items
+func batchStrings(values []string, size int) [][]string {
+	var batches [][]string
+	for start := 0; start < len(values); start += size {
+		end := start + size
+		if end > len(values) {
+			end = len(values)
+		}
+		batches = append(batches, values[start:end])
+	}
+	return batches
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Chart series types
+const (
+	ChartTypeLine    = "line"
+	ChartTypeCandles = "candles"
+)
+
+// DefaultChartRange is the range of lookups that do not pick one
+const DefaultChartRange = "1d"
+
+// chartRange is a selectable price range. CoinGecko returns 5 minute points for 1 day, hourly
+// points up to 90 days and daily points beyond, so intervals below that granularity are rejected.
+type chartRange struct {
+	days            string
+	minInterval     time.Duration
+	defaultInterval time.Duration
+}
+
+var chartRanges = map[string]chartRange{
+	"1d":  {days: "1", minInterval: 5 * time.Minute, defaultInterval: 5 * time.Minute},
+	"7d":  {days: "7", minInterval: time.Hour, defaultInterval: time.Hour},
+	"30d": {days: "30", minInterval: time.Hour, defaultInterval: 4 * time.Hour},
+	"90d": {days: "90", minInterval: time.Hour, defaultInterval: 12 * time.Hour},
+	"1y":  {days: "365", minInterval: 24 * time.Hour, defaultInterval: 24 * time.Hour},
+	"max": {days: "max", minInterval: 24 * time.Hour, defaultInterval: 7 * 24 * time.Hour},
+}
+
+// NewChartOptions validates the chart range, type and interval of a lookup. Empty values use the
+// defaults: 1d, line and the default interval of the range.
+func NewChartOptions(rangeName, chartType, interval string) (dto.ChartOptions, errs.MessageErr) {
+	options := dto.ChartOptions{Range: strings.ToLower(rangeName), Type: strings.ToLower(chartType)}
+	if options.Range == "" {
+		options.Range = DefaultChartRange
+	}
+	if options.Type == "" {
+		options.Type = ChartTypeLine
+	}
+
+	selected, ok := chartRanges[options.Range]
+	if !ok {
+		return options, errs.NewBadRequest("Invalid range. Use 1d, 7d, 30d, 90d, 1y or max.")
+	}
+	if options.Type != ChartTypeLine && options.Type != ChartTypeCandles {
+		return options, errs.NewBadRequest("Invalid chart. Use line or candles.")
+	}
+
+	options.Interval = selected.defaultInterval
+	if interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil {
+			return options, errs.NewBadRequest("Invalid time_skip format. Use formats like 30s, 5m, 1h.")
+		}
+		if parsed < selected.minInterval {
+			return options, errs.NewBadRequest("Time interval must be at least " + formatInterval(selected.minInterval) + " for range " + options.Range)
+		}
+		options.Interval = parsed
+	}
+	return options, nil
+}
+
+// chartDays returns the CoinGecko market chart days of a range
+func chartDays(rangeName string) string {
+	if selected, ok := chartRanges[rangeName]; ok {
+		return selected.days
+	}
+	return chartRanges[DefaultChartRange].days
+}
+
+// applyChart fills the line series of a lookup and its candles when they are requested
+func applyChart(response *dto.ContractAddressResponse, chart *dto.GetPricesRequest, options dto.ChartOptions) {
+	response.ChartRange = options.Range
+	response.ChartInterval = formatInterval(options.Interval)
+	response.TimePrices = filterPricePoints(chart.Prices, options.Interval)
+	if options.Type == ChartTypeCandles {
+		response.Candles = buildCandles(chart.Prices, chart.TotalVolumes, options.Interval)
+	}
+}
+
+// buildCandles aggregates [timestamp ms, price] points into OHLC candles aligned to interval.
+// Each candle's volume is the average of the rolling 24h volumes sampled in it, scaled to interval:
+// a 4h candle gets a sixth of it and a weekly candle seven times it.
+func buildCandles(prices, volumes [][]float64, interval time.Duration) []dto.Candle {
+	if len(prices) == 0 || interval <= 0 {
+		return []dto.Candle{}
+	}
+	width := interval.Milliseconds()
+	bucketOf := func(timestampMs float64) int64 {
+		return int64(timestampMs) / width * width
+	}
+
+	type volumeSum struct {
+		total float64
+		count int
+	}
+	volumeByBucket := make(map[int64]*volumeSum)
+	for _, point := range volumes {
+		if len(point) < 2 {
+			continue
+		}
+		bucket := bucketOf(point[0])
+		if volumeByBucket[bucket] == nil {
+			volumeByBucket[bucket] = &volumeSum{}
+		}
+		volumeByBucket[bucket].total += point[1]
+		volumeByBucket[bucket].count++
+	}
+
+	sorted := make([][]float64, 0, len(prices))
+	for _, point := range prices {
+		if len(point) >= 2 {
+			sorted = append(sorted, point)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+
+	scale := float64(interval) / float64(24*time.Hour)
+	candles := []dto.Candle{}
+	var current *dto.Candle
+	var currentBucket int64
+	for _, point := range sorted {
+		bucket, price := bucketOf(point[0]), point[1]
+		if current == nil || bucket != currentBucket {
+			candles = append(candles, dto.Candle{
+				Timestamp: time.UnixMilli(bucket).UTC().Format(time.RFC3339),
+				Open:      price,
+				High:      price,
+				Low:       price,
+			})
+			current, currentBucket = &candles[len(candles)-1], bucket
+			if volume, ok := volumeByBucket[bucket]; ok {
+				current.Volume = volume.total / float64(volume.count) * scale
+			}
+		}
+		current.High = math.Max(current.High, price)
+		current.Low = math.Min(current.Low, price)
+		current.Close = price
+	}
+	return candles
+}
+
+// formatInterval formats an interval without zero units, e.g. 5m, 4h or 168h
+func formatInterval(interval time.Duration) string {
+	text := interval.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
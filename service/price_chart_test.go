@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildCandlesVolume(t *testing.T) {
+	day := (24 * time.Hour).Milliseconds()
+	prices := [][]float64{{0, 1}, {float64(day), 2}, {float64(2 * day), 3}}
+	volumes := [][]float64{{0, 100}, {float64(day), 200}, {float64(2 * day), 300}}
+
+	tests := []struct {
+		interval time.Duration
+		want     []float64
+	}{
+		{interval: 6 * time.Hour, want: []float64{25, 50, 75}},
+		{interval: 24 * time.Hour, want: []float64{100, 200, 300}},
+		{interval: 7 * 24 * time.Hour, want: []float64{1400}},
+	}
+	for _, tt := range tests {
+		candles := buildCandles(prices, volumes, tt.interval)
+		if len(candles) != len(tt.want) {
+			t.Fatalf("buildCandles(%s) returned %d candles, want %d", tt.interval, len(candles), len(tt.want))
+		}
+		for i, candle := range candles {
+			if candle.Volume != tt.want[i] {
+				t.Errorf("buildCandles(%s)[%d].Volume = %g, want %g", tt.interval, i, candle.Volume, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PriceSubscriptionHub polls prices for the union of every client's subscriptions and delivers
+// each client only the ticks of the assets it subscribed to
+type PriceSubscriptionHub interface {
+	Run(ctx context.Context)
+	Register() *PriceSubscriber
+}
+
+// PriceSubscriber is a single client of the PriceSubscriptionHub
+type PriceSubscriber struct {
+	hub     *priceSubscriptionHub
+	ticks   chan []dto.PriceTick
+	coinIDs map[string]bool
+	mints   map[string]bool
+}
+
+// priceSubscriptionHub implements PriceSubscriptionHub
+type priceSubscriptionHub struct {
+	blockchainSvc BlockchainService
+	interval      time.Duration
+	wake          chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[*PriceSubscriber]struct{}
+}
+
+// NewPriceSubscriptionHub creates a PriceSubscriptionHub polling every interval
+func NewPriceSubscriptionHub(blockchainSvc BlockchainService, interval time.Duration) PriceSubscriptionHub {
+	return &priceSubscriptionHub{
+		blockchainSvc: blockchainSvc,
+		interval:      interval,
+		wake:          make(chan struct{}, 1),
+		subscribers:   make(map[*PriceSubscriber]struct{}),
+	}
+}
+
+// Register adds a subscriber without any subscriptions
+func (h *priceSubscriptionHub) Register() *PriceSubscriber {
+	subscriber := &PriceSubscriber{
+		hub:     h,
+		ticks:   make(chan []dto.PriceTick, subscriberBuffer),
+		coinIDs: make(map[string]bool),
+		mints:   make(map[string]bool),
+	}
+
+	h.mu.Lock()
+	h.subscribers[subscriber] = struct{}{}
+	h.mu.Unlock()
+
+	return subscriber
+}
+
+// Run polls subscribed prices until ctx is done
+func (h *priceSubscriptionHub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.wake:
+		}
+		h.poll(ctx)
+	}
+}
+
+// poll fetches the ticks of every subscribed asset once and fans them out
+func (h *priceSubscriptionHub) poll(ctx context.Context) {
+	coinIDs, mints := h.subscribedAssets()
+	if len(coinIDs) == 0 && len(mints) == 0 {
+		return
+	}
+
+	ticks, err := h.blockchainSvc.GetPriceTicks(ctx, coinIDs, mints)
+	if err != nil {
+		log.Println("Failed to fetch price ticks:", err.Message())
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscriber := range h.subscribers {
+		matched := make([]dto.PriceTick, 0)
+		for _, tick := range ticks {
+			if (tick.Kind == PriceTickCoin && subscriber.coinIDs[tick.Asset]) || (tick.Kind == PriceTickMint && subscriber.mints[tick.Asset]) {
+				matched = append(matched, tick)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		select {
+		case subscriber.ticks <- matched:
+		default:
+			// Ticks are snapshots, so a slow client simply misses this one
+		}
+	}
+}
+
+// subscribedAssets returns the sorted union of every subscriber's coin IDs and mints
+func (h *priceSubscriptionHub) subscribedAssets() ([]string, []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	coinSet := make(map[string]bool)
+	mintSet := make(map[string]bool)
+	for subscriber := range h.subscribers {
+		for id := range subscriber.coinIDs {
+			coinSet[id] = true
+		}
+		for mint := range subscriber.mints {
+			mintSet[mint] = true
+		}
+	}
+	return sortedKeys(coinSet), sortedKeys(mintSet)
+}
+
+// Ticks returns the channel of ticks for the subscriber's assets; it is closed by Close
+func (s *PriceSubscriber) Ticks() <-chan []dto.PriceTick {
+	return s.ticks
+}
+
+// Subscribe adds coin IDs and mints to the subscription and triggers an immediate poll
+func (s *PriceSubscriber) Subscribe(coinIDs, mints []string) {
+	s.hub.mu.Lock()
+	for _, id := range coinIDs {
+		s.coinIDs[id] = true
+	}
+	for _, mint := range mints {
+		s.mints[mint] = true
+	}
+	s.hub.mu.Unlock()
+
+	select {
+	case s.hub.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Unsubscribe removes coin IDs and mints from the subscription
+func (s *PriceSubscriber) Unsubscribe(coinIDs, mints []string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	for _, id := range coinIDs {
+		delete(s.coinIDs, id)
+	}
+	for _, mint := range mints {
+		delete(s.mints, mint)
+	}
+}
+
+// Subscriptions returns the subscriber's current coin IDs and mints
+func (s *PriceSubscriber) Subscriptions() ([]string, []string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return sortedKeys(s.coinIDs), sortedKeys(s.mints)
+}
+
+// Close removes the subscriber from the hub and closes its tick channel
+func (s *PriceSubscriber) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if _, ok := s.hub.subscribers[s]; ok {
+		delete(s.hub.subscribers, s)
+		close(s.ticks)
+	}
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
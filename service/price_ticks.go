@@ -0,0 +1,101 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dexScreenerBatchSize is the maximum number of token addresses DexScreener accepts per request
+const dexScreenerBatchSize = 30
+
+// Price tick kinds
+const (
+	PriceTickCoin = "coin"
+	PriceTickMint = "mint"
+)
+
+// GetPriceTicks returns the latest price, volume and liquidity of CoinGecko coin IDs and Solana mints
+func (s *blockchainService) GetPriceTicks(ctx context.Context, coinIDs, mints []string) ([]dto.PriceTick, errs.MessageErr) {
+	now := time.Now().Format(time.RFC3339)
+	ticks := make([]dto.PriceTick, 0, len(coinIDs)+len(mints))
+
+	if len(coinIDs) > 0 {
+		coins, err := s.marketData.GetMarketsByIDs(ctx, coinIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, coin := range coins {
+			id, _ := coin["id"].(string)
+			symbol, _ := coin["symbol"].(string)
+			price, _ := coin["current_price"].(float64)
+			volume, _ := coin["total_volume"].(float64)
+			ticks = append(ticks, dto.PriceTick{
+				Asset:     id,
+				Kind:      PriceTickCoin,
+				Symbol:    symbol,
+				PriceUSD:  price,
+				Volume24h: volume,
+				Timestamp: now,
+			})
+		}
+	}
+
+	for start := 0; start < len(mints); start += dexScreenerBatchSize {
+		end := start + dexScreenerBatchSize
+		if end > len(mints) {
+			end = len(mints)
+		}
+		batch := mints[start:end]
+
+		pairs, err := s.marketData.GetDexPairs(ctx, solanaPlatformID, strings.Join(batch, ","))
+		if err != nil {
+			return nil, err
+		}
+
+		pairsByMint := make(map[string][]dto.GetLiquidityRequest)
+		for _, pair := range pairs {
+			pairsByMint[pair.BaseToken.Address] = append(pairsByMint[pair.BaseToken.Address], pair)
+		}
+
+		for _, mint := range batch {
+			mintPairs, ok := pairsByMint[mint]
+			if !ok {
+				continue
+			}
+			ticks = append(ticks, mintPriceTick(mint, mintPairs, now))
+		}
+	}
+
+	return ticks, nil
+}
+
+// mintPriceTick aggregates the DEX pairs of a mint into a tick priced from its deepest pool
+func mintPriceTick(mint string, pairs []dto.GetLiquidityRequest, timestamp string) dto.PriceTick {
+	info := buildDexLiquidityInfo(pairs, 0)
+	tick := dto.PriceTick{
+		Asset:     mint,
+		Kind:      PriceTickMint,
+		Volume24h: info.Volume24h,
+		Liquidity: info.LiquidityPoolSize,
+		Timestamp: timestamp,
+	}
+
+	var deepest float64 = -1
+	for _, pair := range pairs {
+		if pair.Liquidity.USD <= deepest {
+			continue
+		}
+		price, err := strconv.ParseFloat(pair.PriceUSD, 64)
+		if err != nil {
+			continue
+		}
+		deepest = pair.Liquidity.USD
+		tick.PriceUSD = price
+		tick.Symbol = pair.BaseToken.Symbol
+	}
+	return tick
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/config"
+	"blockchain-scrap/pkg/errs"
+	httprequest "blockchain-scrap/pkg/http-request"
+	"blockchain-scrap/pkg/prompt"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Prompt templates of the summary analysis
+const (
+	PromptCoinSummary  = "coin_summary"  // lookups by blockchain ID and contract address
+	PromptTokenSummary = "token_summary" // Solana token lookups, a single paragraph
+)
+
+// Summary backends selectable with SUMMARY_BACKEND
+const (
+	SummaryBackendAthenor = "athenor"
+	SummaryBackendOpenAI  = "openai"
+	SummaryBackendFake    = "fake"
+)
+
+const (
+	defaultAthenorEndpoint = "https://casandra-bot.athenor.id/api/preset/completions"
+	defaultOpenAIEndpoint  = "https://api.openai.com/v1/chat/completions"
+	defaultOpenAIModel     = "gpt-4o-mini"
+)
+
+// SummaryRequest is the data to summarize and the prompt to summarize it with
+type SummaryRequest struct {
+	Source   string // SummarySourceAI or SummarySourceLocal, picked by SummaryService
+	Prompt   string // prompt template name
+	Language string // prompt language, prompt.DefaultLanguage when empty or unknown
+	Data     *dto.ContractAddressResponse
+}
+
+// SummaryGenerator writes the AI summary analysis of a token lookup
+type SummaryGenerator interface {
+	Summarize(ctx context.Context, req SummaryRequest) (string, errs.MessageErr)
+}
+
+// SummaryStreamer is implemented by generators that can pass the summary to chunk while it is
+// generated, SummaryService sends the whole summary at once for the others (Athenor and the local summary)
+type SummaryStreamer interface {
+	StreamSummary(ctx context.Context, req SummaryRequest, chunk func(string)) (string, errs.MessageErr)
+}
+
+// SummaryConfig configures the summary backend
+type SummaryConfig struct {
+	Backend   string
+	Endpoint  string
+	APIKey    string
+	Model     string
+	PromptDir string
+	Timeout   time.Duration
+	Source    string // default summary source, local when empty and no AI backend is configured
+}
+
+// SummaryConfigFromEnv reads the summary backend configuration. API_KEY is used when SUMMARY_API_KEY is unset.
+func SummaryConfigFromEnv() SummaryConfig {
+	return SummaryConfig{
+		Backend:   strings.ToLower(config.String("SUMMARY_BACKEND", SummaryBackendAthenor)),
+		Endpoint:  config.String("SUMMARY_ENDPOINT", ""),
+		APIKey:    config.String("SUMMARY_API_KEY", config.String("API_KEY", "")),
+		Model:     config.String("SUMMARY_MODEL", defaultOpenAIModel),
+		PromptDir: config.String("SUMMARY_PROMPT_DIR", ""),
+		Timeout:   config.Duration("SUMMARY_TIMEOUT", 60*time.Second),
+		Source:    strings.ToLower(config.String("SUMMARY_SOURCE", "")),
+	}
+}
+
+// DefaultSource is the summary source of requests that do not pick one
+func (cfg SummaryConfig) DefaultSource() string {
+	switch cfg.Source {
+	case SummarySourceAI, SummarySourceLocal:
+		return cfg.Source
+	case "":
+	default:
+		log.Printf("Invalid SUMMARY_SOURCE %q, using the default", cfg.Source)
+	}
+	if cfg.APIKey == "" && cfg.Backend != SummaryBackendFake && (cfg.Backend != SummaryBackendOpenAI || cfg.Endpoint == "") {
+		return SummarySourceLocal
+	}
+	return SummarySourceAI
+}
+
+// NewSummaryGenerator creates the summary backend selected by cfg
+func NewSummaryGenerator(cfg SummaryConfig, prompts *prompt.Templates) (SummaryGenerator, error) {
+	switch cfg.Backend {
+	case SummaryBackendAthenor, "":
+		return &athenorSummaryGenerator{
+			endpoint: firstNonEmpty(cfg.Endpoint, defaultAthenorEndpoint),
+			apiKey:   cfg.APIKey,
+			prompts:  prompts,
+			timeout:  cfg.Timeout,
+		}, nil
+	case SummaryBackendOpenAI:
+		return &openAISummaryGenerator{
+			endpoint: firstNonEmpty(cfg.Endpoint, defaultOpenAIEndpoint),
+			apiKey:   cfg.APIKey,
+			model:    cfg.Model,
+			prompts:  prompts,
+			timeout:  cfg.Timeout,
+		}, nil
+	case SummaryBackendFake:
+		return NewFakeSummaryGenerator(), nil
+	default:
+		return nil, fmt.Errorf("unknown SUMMARY_BACKEND %q", cfg.Backend)
+	}
+}
+
+// athenorSummaryGenerator sends the prompt and the lookup to the Athenor preset completions API.
+// The preset API has no streaming mode, so it does not implement SummaryStreamer.
+type athenorSummaryGenerator struct {
+	endpoint string
+	apiKey   string
+	prompts  *prompt.Templates
+	timeout  time.Duration
+}
+
+func (g *athenorSummaryGenerator) Summarize(ctx context.Context, req SummaryRequest) (string, errs.MessageErr) {
+	text, err := renderSummaryPrompt(g.prompts, req)
+	if err != nil {
+		return "", err
+	}
+
+	body, errMarshal := json.Marshal(&dto.AIRequest{Prompt: text, Collections: *req.Data})
+	if errMarshal != nil {
+		return "", errs.NewInternalServerError("Failed to process data for AI")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	header := map[string]string{"ATHENOR-API-KEY": g.apiKey}
+	resp, errRequest := httprequest.ProcessJSONRequestWithContext(ctx, "POST", g.endpoint, body, header)
+	if errRequest != nil {
+		return "", errs.NewInternalServerError("Failed to process AI response")
+	}
+
+	aiResponse := &dto.AIResponse{}
+	if errUnmarshal := json.Unmarshal(resp, aiResponse); errUnmarshal != nil {
+		return "", errs.NewInternalServerError("Failed to process AI response")
+	}
+	return aiResponse.AssistantMessage, nil
+}
+
+// openAISummaryGenerator sends the prompt as the system message and the lookup as the user
+// message to an OpenAI-compatible chat completions API
+type openAISummaryGenerator struct {
+	endpoint string
+	apiKey   string
+	model    string
+	prompts  *prompt.Templates
+	timeout  time.Duration
+}
+
+func (g *openAISummaryGenerator) Summarize(ctx context.Context, req SummaryRequest) (string, errs.MessageErr) {
+	body, err := g.requestBody(req, false)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	resp, errRequest := httprequest.ProcessJSONRequestWithContext(ctx, "POST", g.endpoint, body, g.headers())
+	if errRequest != nil {
+		return "", errs.NewInternalServerError("Failed to process AI response")
+	}
+
+	completion := &dto.ChatCompletionResponse{}
+	if errUnmarshal := json.Unmarshal(resp, completion); errUnmarshal != nil || len(completion.Choices) == 0 {
+		return "", errs.NewInternalServerError("Failed to process AI response")
+	}
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
+
+// StreamSummary reads the server-sent chunks of a streamed chat completion
+func (g *openAISummaryGenerator) StreamSummary(ctx context.Context, req SummaryRequest, chunk func(string)) (string, errs.MessageErr) {
+	body, err := g.requestBody(req, true)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	stream, errRequest := httprequest.ProcessStreamRequest(ctx, "POST", g.endpoint, body, g.headers())
+	if errRequest != nil {
+		return "", errs.NewInternalServerError("Failed to process AI response")
+	}
+	defer stream.Close()
+
+	var summary strings.Builder
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		completion := &dto.ChatCompletionChunk{}
+		if errUnmarshal := json.Unmarshal([]byte(data), completion); errUnmarshal != nil {
+			return summary.String(), errs.NewInternalServerError("Failed to process AI response")
+		}
+		for _, choice := range completion.Choices {
+			if choice.Delta.Content != "" {
+				summary.WriteString(choice.Delta.Content)
+				chunk(choice.Delta.Content)
+			}
+		}
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		return summary.String(), errs.NewInternalServerError("Failed to process AI response")
+	}
+	return strings.TrimSpace(summary.String()), nil
+}
+
+// requestBody builds the chat completion request of a summary
+func (g *openAISummaryGenerator) requestBody(req SummaryRequest, stream bool) ([]byte, errs.MessageErr) {
+	text, err := renderSummaryPrompt(g.prompts, req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, errMarshal := json.Marshal(req.Data)
+	if errMarshal != nil {
+		return nil, errs.NewInternalServerError("Failed to process data for AI")
+	}
+	body, errMarshal := json.Marshal(&dto.ChatCompletionRequest{
+		Model: g.model,
+		Messages: []dto.ChatMessage{
+			{Role: "system", Content: text},
+			{Role: "user", Content: string(data)},
+		},
+		Stream: stream,
+	})
+	if errMarshal != nil {
+		return nil, errs.NewInternalServerError("Failed to process data for AI")
+	}
+	return body, nil
+}
+
+func (g *openAISummaryGenerator) headers() map[string]string {
+	if g.apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + g.apiKey}
+}
+
+// fakeSummaryGenerator builds the summary from the lookup itself, for tests and local development
+type fakeSummaryGenerator struct{}
+
+// NewFakeSummaryGenerator creates a SummaryGenerator whose summary only depends on the request
+func NewFakeSummaryGenerator() SummaryGenerator {
+	return fakeSummaryGenerator{}
+}
+
+// StreamSummary passes the fake summary to chunk word by word
+func (g fakeSummaryGenerator) StreamSummary(ctx context.Context, req SummaryRequest, chunk func(string)) (string, errs.MessageErr) {
+	summary, _ := g.Summarize(ctx, req)
+	for i, word := range strings.Fields(summary) {
+		if i > 0 {
+			word = " " + word
+		}
+		chunk(word)
+	}
+	return summary, nil
+}
+
+func (fakeSummaryGenerator) Summarize(ctx context.Context, req SummaryRequest) (string, errs.MessageErr) {
+	data := req.Data
+	summary := fmt.Sprintf("[%s/%s] %s (%s) trades at $%g with a market cap of $%.0f and a 24h volume of $%.0f.",
+		req.Prompt, firstNonEmpty(req.Language, prompt.DefaultLanguage), data.ID, strings.ToUpper(data.Symbol),
+		data.MarketData.CurrentPrice.USD, data.MarketData.MarketCap.USD, data.MarketData.TotalVolume.USD)
+	if data.Risk != nil {
+		summary += fmt.Sprintf(" Risk is %s (%d/100).", data.Risk.Level, data.Risk.Score)
+	}
+	return summary, nil
+}
+
+// renderSummaryPrompt renders the prompt template of a summary request
+func renderSummaryPrompt(prompts *prompt.Templates, req SummaryRequest) (string, errs.MessageErr) {
+	text, err := prompts.Render(req.Prompt, req.Language, req.Data)
+	if err != nil {
+		return "", errs.NewInternalServerError("Failed to build AI prompt: " + err.Error())
+	}
+	return text, nil
+}
+
+// firstNonEmpty returns the first value that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/prompt"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewSummaryGenerator(t *testing.T) {
+	prompts, err := prompt.Load("")
+	if err != nil {
+		t.Fatalf("prompt.Load: %v", err)
+	}
+
+	tests := []struct {
+		backend  string
+		endpoint string
+		want     string
+	}{
+		{backend: "", want: defaultAthenorEndpoint},
+		{backend: SummaryBackendAthenor, endpoint: "http://athenor.local", want: "http://athenor.local"},
+		{backend: SummaryBackendOpenAI, want: defaultOpenAIEndpoint},
+		{backend: SummaryBackendOpenAI, endpoint: "http://localhost:11434/v1/chat/completions", want: "http://localhost:11434/v1/chat/completions"},
+	}
+	for _, tt := range tests {
+		generator, err := NewSummaryGenerator(SummaryConfig{Backend: tt.backend, Endpoint: tt.endpoint}, prompts)
+		if err != nil {
+			t.Fatalf("NewSummaryGenerator(%q): %v", tt.backend, err)
+		}
+
+		var endpoint string
+		switch g := generator.(type) {
+		case *athenorSummaryGenerator:
+			endpoint = g.endpoint
+		case *openAISummaryGenerator:
+			endpoint = g.endpoint
+		default:
+			t.Fatalf("NewSummaryGenerator(%q) = %T", tt.backend, generator)
+		}
+		if endpoint != tt.want {
+			t.Errorf("NewSummaryGenerator(%q) endpoint = %q, want %q", tt.backend, endpoint, tt.want)
+		}
+	}
+
+	if _, err := NewSummaryGenerator(SummaryConfig{Backend: "unknown"}, prompts); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestFakeSummaryGenerator(t *testing.T) {
+	generator, err := NewSummaryGenerator(SummaryConfig{Backend: SummaryBackendFake}, nil)
+	if err != nil {
+		t.Fatalf("NewSummaryGenerator: %v", err)
+	}
+
+	data := &dto.ContractAddressResponse{ID: "dai", Symbol: "dai"}
+	data.MarketData.CurrentPrice.USD = 1
+	req := SummaryRequest{Prompt: PromptTokenSummary, Data: data}
+
+	summary, errSummary := generator.Summarize(context.Background(), req)
+	if errSummary != nil {
+		t.Fatalf("Summarize: %s", errSummary.Message())
+	}
+	if !strings.HasPrefix(summary, "[token_summary/en] dai (DAI) trades at $1") {
+		t.Errorf("Summarize = %q", summary)
+	}
+
+	var chunks []string
+	streamed, _ := generator.(SummaryStreamer).StreamSummary(context.Background(), req, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if streamed != summary || strings.Join(chunks, "") != summary {
+		t.Errorf("StreamSummary = %q from chunks %q, want %q", streamed, chunks, summary)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/cache"
+	"blockchain-scrap/pkg/config"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SummaryService makes the AI summary an optional enrichment of a lookup, a failing or slow
+// backend never fails the lookup itself
+type SummaryService interface {
+	// Summarize generates a summary within the inline timeout and reports how it went
+	Summarize(ctx context.Context, req SummaryRequest) (summary, status string)
+	// Stream generates a summary within the stream timeout, passing its text to chunk as it is generated
+	Stream(ctx context.Context, req SummaryRequest, chunk func(string)) (summary, status string)
+	// Streams reports whether the generator of source streams its summary, Stream passes the whole
+	// summary to chunk at once otherwise
+	Streams(source string) bool
+	// Enqueue generates a summary in the background within the job timeout, its job can be read with
+	// FindJob. It reports false without starting a job when MaxJobs summaries are already being generated.
+	Enqueue(req SummaryRequest) (uuid.UUID, bool)
+	FindJob(ctx context.Context, id uuid.UUID) (*dto.SummaryJob, errs.MessageErr)
+	// DefaultSource is the source of requests that do not pick one
+	DefaultSource() string
+}
+
+// SummaryServiceConfig bounds how long and how many summaries SummaryService generates
+type SummaryServiceConfig struct {
+	MaxJobs       int           // background jobs generated at once
+	InlineTimeout time.Duration // summaries returned with the lookup
+	StreamTimeout time.Duration // summaries streamed with the lookup
+	JobTimeout    time.Duration // summaries generated in the background
+	JobTTL        time.Duration // how long finished jobs can be read
+}
+
+// SummaryServiceConfigFromEnv reads SUMMARY_MAX_JOBS, SUMMARY_INLINE_TIMEOUT, SUMMARY_STREAM_TIMEOUT,
+// SUMMARY_JOB_TIMEOUT and SUMMARY_JOB_TTL
+func SummaryServiceConfigFromEnv() SummaryServiceConfig {
+	return SummaryServiceConfig{
+		MaxJobs:       config.Int("SUMMARY_MAX_JOBS", 16),
+		InlineTimeout: config.Duration("SUMMARY_INLINE_TIMEOUT", 15*time.Second),
+		StreamTimeout: config.Duration("SUMMARY_STREAM_TIMEOUT", 60*time.Second),
+		JobTimeout:    config.Duration("SUMMARY_JOB_TIMEOUT", 2*time.Minute),
+		JobTTL:        config.Duration("SUMMARY_JOB_TTL", time.Hour),
+	}
+}
+
+type summaryService struct {
+	generators    map[string]SummaryGenerator
+	defaultSource string
+	jobs          cache.Store
+	jobSlots      chan struct{}
+	cfg           SummaryServiceConfig
+}
+
+// NewSummaryService creates a SummaryService writing AI summaries with ai and rule-based summaries with
+// local, keeping its background jobs in store
+func NewSummaryService(ai, local SummaryGenerator, defaultSource string, store cache.Store, cfg SummaryServiceConfig) SummaryService {
+	if cfg.MaxJobs <= 0 {
+		cfg.MaxJobs = 1
+	}
+	return &summaryService{
+		generators:    map[string]SummaryGenerator{SummarySourceAI: ai, SummarySourceLocal: local},
+		defaultSource: defaultSource,
+		jobs:          store,
+		jobSlots:      make(chan struct{}, cfg.MaxJobs),
+		cfg:           cfg,
+	}
+}
+
+func (s *summaryService) DefaultSource() string {
+	return s.defaultSource
+}
+
+// generator returns the generator of the request's source, the default one when it is unknown
+func (s *summaryService) generator(req SummaryRequest) SummaryGenerator {
+	if generator, ok := s.generators[req.Source]; ok {
+		return generator
+	}
+	return s.generators[s.defaultSource]
+}
+
+func (s *summaryService) Summarize(ctx context.Context, req SummaryRequest) (string, string) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.InlineTimeout)
+	defer cancel()
+
+	summary, err := s.generator(req).Summarize(ctx, req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Println("Summary timed out:", req.Data.Platform)
+			return "", dto.SummaryStatusTimeout
+		}
+		log.Println("Failed to generate summary:", req.Data.Platform, err.Message())
+		return "", dto.SummaryStatusError
+	}
+	return summary, dto.SummaryStatusOK
+}
+
+func (s *summaryService) Stream(ctx context.Context, req SummaryRequest, chunk func(string)) (string, string) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.StreamTimeout)
+	defer cancel()
+
+	var (
+		summary string
+		err     errs.MessageErr
+	)
+	if streamer, ok := s.generator(req).(SummaryStreamer); ok {
+		summary, err = streamer.StreamSummary(ctx, req, chunk)
+	} else if summary, err = s.generator(req).Summarize(ctx, req); err == nil {
+		chunk(summary)
+	}
+
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Println("Summary stream timed out:", req.Data.Platform)
+			return summary, dto.SummaryStatusTimeout
+		}
+		log.Println("Failed to stream summary:", req.Data.Platform, err.Message())
+		return summary, dto.SummaryStatusError
+	}
+	return summary, dto.SummaryStatusOK
+}
+
+func (s *summaryService) Streams(source string) bool {
+	_, ok := s.generator(SummaryRequest{Source: source}).(SummaryStreamer)
+	return ok
+}
+
+func (s *summaryService) Enqueue(req SummaryRequest) (uuid.UUID, bool) {
+	select {
+	case s.jobSlots <- struct{}{}:
+	default:
+		log.Println("Too many summary jobs, skipping summary:", req.Data.Platform)
+		return uuid.Nil, false
+	}
+
+	// The caller keeps using its response, the job works on its own copy
+	data := *req.Data
+	req.Data = &data
+
+	job := &dto.SummaryJob{ID: uuid.New(), Source: req.Source, Status: dto.SummaryStatusPending, CreatedAt: time.Now()}
+	s.saveJob(job)
+
+	go func() {
+		defer func() { <-s.jobSlots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.JobTimeout)
+		defer cancel()
+
+		summary, err := s.generator(req).Summarize(ctx, req)
+		completedAt := time.Now()
+		job.CompletedAt = &completedAt
+		switch {
+		case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+			log.Println("Summary job timed out:", req.Data.Platform)
+			job.Status = dto.SummaryStatusTimeout
+		case err != nil:
+			log.Println("Failed to generate summary:", req.Data.Platform, err.Message())
+			job.Status = dto.SummaryStatusError
+			job.Error = err.Message()
+		default:
+			job.Status = dto.SummaryStatusOK
+			job.Summary = summary
+		}
+		s.saveJob(job)
+	}()
+
+	return job.ID, true
+}
+
+func (s *summaryService) FindJob(ctx context.Context, id uuid.UUID) (*dto.SummaryJob, errs.MessageErr) {
+	entry, ok := s.jobs.Get(ctx, summaryJobKey(id))
+	if !ok {
+		return nil, errs.NewNotFound("Summary not found")
+	}
+
+	job := &dto.SummaryJob{}
+	if err := json.Unmarshal(entry.Value, job); err != nil {
+		return nil, errs.NewInternalServerError("Failed to read summary")
+	}
+	return job, nil
+}
+
+// saveJob stores a copy of the job's current state
+func (s *summaryService) saveJob(job *dto.SummaryJob) {
+	value, err := json.Marshal(job)
+	if err != nil {
+		log.Println("Failed to store summary job:", job.ID, err)
+		return
+	}
+	s.jobs.Set(context.Background(), summaryJobKey(job.ID), value, s.cfg.JobTTL)
+}
+
+func summaryJobKey(id uuid.UUID) string {
+	return "summary-job:" + id.String()
+}
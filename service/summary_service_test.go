@@ -0,0 +1,80 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/cache"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"strings"
+	"testing"
+)
+
+// wholeSummaryGenerator returns its summary at once, like the Athenor backend
+type wholeSummaryGenerator struct {
+	summary string
+}
+
+func (g wholeSummaryGenerator) Summarize(ctx context.Context, req SummaryRequest) (string, errs.MessageErr) {
+	return g.summary, nil
+}
+
+func TestSummaryServiceStream(t *testing.T) {
+	data := &dto.ContractAddressResponse{ID: "dai", Symbol: "dai"}
+	fake := NewFakeSummaryGenerator()
+	fakeSummary, _ := fake.Summarize(context.Background(), SummaryRequest{Prompt: PromptTokenSummary, Data: data})
+
+	tests := []struct {
+		name        string
+		ai          SummaryGenerator
+		wantStreams bool
+		wantChunks  int
+		wantSummary string
+	}{
+		{
+			name:        "streaming backend",
+			ai:          fake,
+			wantStreams: true,
+			wantChunks:  len(strings.Fields(fakeSummary)),
+			wantSummary: fakeSummary,
+		},
+		{
+			name:        "backend without streaming",
+			ai:          wholeSummaryGenerator{summary: "DAI trades at $1."},
+			wantStreams: false,
+			wantChunks:  1,
+			wantSummary: "DAI trades at $1.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewSummaryService(tt.ai, wholeSummaryGenerator{summary: "local"}, SummarySourceAI,
+				cache.NewMemoryStore(8), SummaryServiceConfigFromEnv())
+
+			if got := svc.Streams(SummarySourceAI); got != tt.wantStreams {
+				t.Errorf("Streams(ai) = %v, want %v", got, tt.wantStreams)
+			}
+			if svc.Streams(SummarySourceLocal) {
+				t.Error("Streams(local) = true, want false")
+			}
+
+			var chunks []string
+			summary, status := svc.Stream(context.Background(),
+				SummaryRequest{Source: SummarySourceAI, Prompt: PromptTokenSummary, Data: data},
+				func(text string) { chunks = append(chunks, text) })
+
+			if status != dto.SummaryStatusOK {
+				t.Errorf("status = %q, want %q", status, dto.SummaryStatusOK)
+			}
+			if summary != tt.wantSummary {
+				t.Errorf("summary = %q, want %q", summary, tt.wantSummary)
+			}
+			if len(chunks) != tt.wantChunks {
+				t.Errorf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+			if joined := strings.Join(chunks, ""); joined != tt.wantSummary {
+				t.Errorf("chunks = %q, want them to add up to %q", joined, tt.wantSummary)
+			}
+		})
+	}
+}
@@ -5,42 +5,60 @@ import (
 	"blockchain-scrap/entity"
 	"blockchain-scrap/pkg/errs"
 	httprequest "blockchain-scrap/pkg/http-request"
+	"blockchain-scrap/pkg/pagination"
 	"blockchain-scrap/repository"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math"
 	"strconv"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/google/uuid"
 )
 
 type SwapService interface {
-	GetSwapTransaction(req dto.SwapRequest) (string, errs.MessageErr)
-	SubmitTransaction(req dto.SubmitRequest) (string, errs.MessageErr)
+	GetSwapTransaction(ctx context.Context, userID uuid.UUID, req dto.SwapRequest) (*dto.SwapResponse, errs.MessageErr)
+	SubmitTransaction(ctx context.Context, userID uuid.UUID, req dto.SubmitRequest) (*dto.SubmitResponse, errs.MessageErr)
 	GetCurrencySwap(req dto.SwapRequest) (*dto.GetCurrencySwapResponse, errs.MessageErr)
+	GetSwapStatus(ctx context.Context, userID uuid.UUID, signature string) (*dto.SwapStatusResponse, errs.MessageErr)
+	FindSwapsByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*dto.SwapHistory, errs.MessageErr)
 }
 
 type swapServiceImpl struct {
 	tokenRepo    repository.TokenRepository
+	swapRepo     repository.SwapRepository
 	tokenService TokenService
+	client       *rpc.Client
+	allowed      map[solana.PublicKey]bool
 }
 
-func NewSwapService(tokenRepo repository.TokenRepository, tokenService TokenService) SwapService {
-	return &swapServiceImpl{tokenRepo: tokenRepo, tokenService: tokenService}
+func NewSwapService(tokenRepo repository.TokenRepository, swapRepo repository.SwapRepository, tokenService TokenService, client *rpc.Client) SwapService {
+	return &swapServiceImpl{
+		tokenRepo:    tokenRepo,
+		swapRepo:     swapRepo,
+		tokenService: tokenService,
+		client:       client,
+		allowed:      swapAllowedPrograms(),
+	}
 }
 
-func (s *swapServiceImpl) GetSwapTransaction(req dto.SwapRequest) (string, errs.MessageErr) {
+func (s *swapServiceImpl) GetSwapTransaction(ctx context.Context, userID uuid.UUID, req dto.SwapRequest) (*dto.SwapResponse, errs.MessageErr) {
 
 	var (
 		decimalAmount int64
 	)
 
+	if errValidate := validateSwapOptions(req); errValidate != nil {
+		return nil, errValidate
+	}
+
 	tokenMetadatas, errRepo := s.tokenRepo.FindByAddress([]string{req.InputMint, req.OutputMint})
 	if errRepo != nil {
-		return "", errRepo
+		return nil, errRepo
 	}
 
 	tokenMap := make(map[string]*entity.Token)
@@ -50,12 +68,12 @@ func (s *swapServiceImpl) GetSwapTransaction(req dto.SwapRequest) (string, errs.
 
 	inputTokenMeta, ok := tokenMap[req.InputMint]
 	if !ok {
-		return "", errs.NewBadRequest("token sell not supported")
+		return nil, errs.NewBadRequest("token sell not supported")
 	}
 
 	_, ok = tokenMap[req.OutputMint]
 	if !ok {
-		return "", errs.NewBadRequest("token buy not supported")
+		return nil, errs.NewBadRequest("token buy not supported")
 	}
 
 	if inputTokenMeta.Decimals > 0 {
@@ -64,56 +82,70 @@ func (s *swapServiceImpl) GetSwapTransaction(req dto.SwapRequest) (string, errs.
 		decimalAmount = int64(req.Amount)
 	}
 
-	quoteURL := fmt.Sprintf(
-		"https://quote-api.jup.ag/v6/quote?inputMint=%s&outputMint=%s&amount=%d&slippageBps=50",
-		req.InputMint, req.OutputMint, decimalAmount,
-	)
+	quoteURL := buildQuoteURL(req, decimalAmount)
 
 	body, errRequest := httprequest.ProcessJSONRequest("GET", quoteURL, nil, nil)
 	if errRequest != nil {
 		var jupError dto.JupiterErrorResponse
 		if unmarshalErr := json.Unmarshal(body, &jupError); unmarshalErr == nil && jupError.Error != "" {
-			return "", errs.NewBadRequest(fmt.Sprintf("Jupiter API error: %s (Code: %s)", jupError.Error, jupError.ErrorCode))
+			return nil, errs.NewBadRequest(fmt.Sprintf("Jupiter API error: %s (Code: %s)", jupError.Error, jupError.ErrorCode))
 		}
-		return "", errs.NewInternalServerError(fmt.Sprintf("Failed quote from Jupiter API: %s", errRequest.Message()))
+		return nil, errs.NewInternalServerError(fmt.Sprintf("Failed quote from Jupiter API: %s", errRequest.Message()))
 	}
 
 	var quoteResponse map[string]interface{}
 	if err := json.Unmarshal(body, &quoteResponse); err != nil {
-		return "", errs.NewInternalServerError(fmt.Sprintf("failed unmarshal respons quote Jupiter: %v", err))
+		return nil, errs.NewInternalServerError(fmt.Sprintf("failed unmarshal respons quote Jupiter: %v", err))
 	}
 
-	swapPayload := map[string]interface{}{
-		"quoteResponse": quoteResponse,
-		"userPublicKey": req.PublicKey,
-		"wrapUnwrapSOL": true,
+	var quote dto.QuoteResponse
+	if err := json.Unmarshal(body, &quote); err != nil {
+		return nil, errs.NewInternalServerError(fmt.Sprintf("failed unmarshal respons quote Jupiter: %v", err))
 	}
 
+	swapPayload := buildSwapPayload(req, quoteResponse)
+
 	swapPayloadBytes, err := json.Marshal(swapPayload)
 	if err != nil {
-		return "", errs.NewInternalServerError(fmt.Sprintf("failed marshal swap payload: %v", err))
+		return nil, errs.NewInternalServerError(fmt.Sprintf("failed marshal swap payload: %v", err))
 	}
 
-	swapBody, errRequest := httprequest.ProcessJSONRequest("POST", "https://quote-api.jup.ag/v6/swap", swapPayloadBytes, nil)
+	swapBody, errRequest := httprequest.ProcessJSONRequest("POST", jupiterSwapURL, swapPayloadBytes, nil)
 	if errRequest != nil {
 		var jupError dto.JupiterErrorResponse
 		if unmarshalErr := json.Unmarshal(swapBody, &jupError); unmarshalErr == nil && jupError.Error != "" {
-			return "", errs.NewBadRequest(fmt.Sprintf("Jupiter API error: %s (Code: %s)", jupError.Error, jupError.ErrorCode))
+			return nil, errs.NewBadRequest(fmt.Sprintf("Jupiter API error: %s (Code: %s)", jupError.Error, jupError.ErrorCode))
 		}
-		return "", errs.NewInternalServerError(fmt.Sprintf("Failed swap from Jupiter API: %s", errRequest.Message()))
+		return nil, errs.NewInternalServerError(fmt.Sprintf("Failed swap from Jupiter API: %s", errRequest.Message()))
 	}
 
 	var swapResponse map[string]interface{}
 	if err := json.Unmarshal(swapBody, &swapResponse); err != nil {
-		return "", errs.NewInternalServerError(fmt.Sprintf("failed unmarshal respons swap Jupiter: %v", err))
+		return nil, errs.NewInternalServerError(fmt.Sprintf("failed unmarshal respons swap Jupiter: %v", err))
 	}
 
 	transaction, ok := swapResponse["swapTransaction"].(string)
 	if !ok {
-		return "", errs.NewInternalServerError("invalid swapTransaction format")
+		return nil, errs.NewInternalServerError("invalid swapTransaction format")
 	}
 
-	return transaction, nil
+	swap := &entity.Swap{
+		UserID:          userID,
+		PublicKey:       req.PublicKey,
+		InputMint:       req.InputMint,
+		OutputMint:      req.OutputMint,
+		InAmount:        req.Amount,
+		OutAmount:       toUIAmount(quote.OutAmount, req.OutputMint, tokenMap),
+		MinimumReceived: toUIAmount(quote.OtherAmountThreshold, req.OutputMint, tokenMap),
+		SlippageBps:     quote.SlippageBps,
+		Quote:           body,
+		Status:          entity.SwapStatusCreated,
+	}
+	if errSave := s.swapRepo.Create(ctx, swap); errSave != nil {
+		return nil, errSave
+	}
+
+	return &dto.SwapResponse{SwapID: swap.ID, Transaction: transaction}, nil
 }
 
 func parseFloat(s string, fieldName string) (float64, errs.MessageErr) {
@@ -134,13 +166,15 @@ func (s *swapServiceImpl) GetCurrencySwap(req dto.SwapRequest) (*dto.GetCurrency
 		decimalAmount       int64
 	)
 
+	if errValidate := validateSwapOptions(req); errValidate != nil {
+		return nil, errValidate
+	}
+
 	tokenMetadatas, errRepo := s.tokenRepo.FindByAddress([]string{req.InputMint, req.OutputMint})
 	if errRepo != nil {
 		return nil, errRepo
 	}
 
-	fmt.Println(tokenMetadatas[0].Address)
-
 	tokenMap := make(map[string]*entity.Token)
 	for _, t := range tokenMetadatas {
 		tokenMap[t.Address] = t
@@ -161,10 +195,7 @@ func (s *swapServiceImpl) GetCurrencySwap(req dto.SwapRequest) (*dto.GetCurrency
 		decimalAmount = int64(req.Amount)
 	}
 
-	quoteURL := fmt.Sprintf(
-		"https://quote-api.jup.ag/v6/quote?inputMint=%s&outputMint=%s&amount=%d&slippageBps=50",
-		req.InputMint, req.OutputMint, decimalAmount,
-	)
+	quoteURL := buildQuoteURL(req, decimalAmount)
 
 	body, errRequest := httprequest.ProcessJSONRequest("GET", quoteURL, nil, nil)
 	if errRequest != nil {
@@ -238,22 +269,78 @@ func (s *swapServiceImpl) GetCurrencySwap(req dto.SwapRequest) (*dto.GetCurrency
 		getCurrencyResponse.IsSwappable = false
 	}
 
+	if routeTokens, errRoute := s.tokenRepo.FindByAddress(routeMints(quoteResponse)); errRoute == nil {
+		for _, t := range routeTokens {
+			tokenMap[t.Address] = t
+		}
+	}
+	applyQuoteDetails(&getCurrencyResponse, quoteResponse, tokenMap, time.Now())
+
 	return &getCurrencyResponse, nil
 }
 
-func (s *swapServiceImpl) SubmitTransaction(req dto.SubmitRequest) (string, errs.MessageErr) {
-	client := rpc.New("https://api.mainnet-beta.solana.com")
-
+func (s *swapServiceImpl) SubmitTransaction(ctx context.Context, userID uuid.UUID, req dto.SubmitRequest) (*dto.SubmitResponse, errs.MessageErr) {
 	tx, err := solana.TransactionFromBase64(req.SignedTransaction)
 	if err != nil {
-		return "", errs.NewInternalServerError(fmt.Sprintf("failed to decode transaction: %v", err))
+		return nil, errs.NewBadRequest(fmt.Sprintf("failed to decode transaction: %v", err))
+	}
+
+	// The swap ties the transaction to the public key that requested the quote
+	if req.SwapID == nil {
+		return nil, errs.NewBadRequest("swapId is required")
+	}
+	swap, errFind := s.swapRepo.FindByID(ctx, *req.SwapID)
+	if errFind != nil {
+		return nil, errFind
+	}
+	if swap.UserID != userID {
+		return nil, errs.NewNotFound("Swap not found")
+	}
+	if swap.Status != entity.SwapStatusCreated {
+		return nil, errs.NewConflict("Swap has already been submitted")
+	}
+
+	if errValidate := validateSignedTransaction(tx, swap.PublicKey, s.allowed); errValidate != nil {
+		return nil, errValidate
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Claim the swap before anything is sent so concurrent submits of the same swap cannot both relay it
+	submittedAt := time.Now()
+	claimed, errClaim := s.swapRepo.ClaimForSubmission(ctx, swap.ID, submittedAt)
+	if errClaim != nil {
+		return nil, errClaim
+	}
+	if !claimed {
+		return nil, errs.NewConflict("Swap has already been submitted")
+	}
+	// release hands the swap back when the transaction was rejected before reaching the cluster
+	release := func() {
+		if errRelease := s.swapRepo.ReleaseClaim(context.WithoutCancel(ctx), swap.ID); errRelease != nil {
+			log.Printf("Failed to release swap %s: %s", swap.ID, errRelease.Message())
+		}
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	sig, err := client.SendTransactionWithOpts(
-		ctx,
+	if req.Simulate {
+		simulation, err := s.client.SimulateTransactionWithOpts(sendCtx, tx, &rpc.SimulateTransactionOpts{
+			SigVerify:  true,
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		if err != nil || simulation.Value == nil {
+			release()
+			return nil, errs.NewInternalServerError("failed to simulate transaction")
+		}
+		if simulation.Value.Err != nil {
+			release()
+			return &dto.SubmitResponse{SwapID: swap.ID, Status: swap.Status, Simulation: toSimulationResult(simulation.Value)},
+				errs.NewUnprocessableEntity("transaction simulation failed")
+		}
+	}
+
+	sig, err := s.client.SendTransactionWithOpts(
+		sendCtx,
 		tx,
 		rpc.TransactionOpts{
 			SkipPreflight:       false,
@@ -261,8 +348,85 @@ func (s *swapServiceImpl) SubmitTransaction(req dto.SubmitRequest) (string, errs
 		},
 	)
 	if err != nil {
-		return "", errs.NewInternalServerError(fmt.Sprintf("failed to send transaction: %v", err))
+		message, simulation := preflightFailure(err)
+		if simulation != nil {
+			release()
+			return &dto.SubmitResponse{SwapID: swap.ID, Status: swap.Status, Simulation: simulation},
+				errs.NewUnprocessableEntity(fmt.Sprintf("transaction rejected: %s", message))
+		}
+		// The transaction may still have reached the cluster, track its signature so the
+		// confirmer settles or expires the swap instead of allowing a second submit
+		if errSave := s.markSubmitted(ctx, swap, tx.Signatures[0], submittedAt); errSave != nil {
+			log.Printf("Failed to save swap %s: %s", swap.ID, errSave.Message())
+		}
+		return nil, errs.NewInternalServerError(fmt.Sprintf("failed to send transaction: %s", message))
 	}
 
-	return sig.String(), nil
+	if errSave := s.markSubmitted(ctx, swap, sig, submittedAt); errSave != nil {
+		return nil, errSave
+	}
+
+	return &dto.SubmitResponse{SwapID: swap.ID, Signature: sig.String(), Status: swap.Status}, nil
+}
+
+// markSubmitted stores the signature of a claimed swap, even when the request was cancelled after sending
+func (s *swapServiceImpl) markSubmitted(ctx context.Context, swap *entity.Swap, sig solana.Signature, submittedAt time.Time) errs.MessageErr {
+	signature := sig.String()
+	swap.Signature = &signature
+	swap.Status = entity.SwapStatusSubmitted
+	swap.SubmittedAt = &submittedAt
+	return s.swapRepo.Save(context.WithoutCancel(ctx), swap)
+}
+
+// GetSwapStatus returns a swap of the user by its transaction signature
+func (s *swapServiceImpl) GetSwapStatus(ctx context.Context, userID uuid.UUID, signature string) (*dto.SwapStatusResponse, errs.MessageErr) {
+	swap, err := s.swapRepo.FindBySignature(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	if swap.UserID != userID {
+		return nil, errs.NewNotFound("Swap not found")
+	}
+	response := toSwapStatusResponse(swap)
+	return &response, nil
+}
+
+// FindSwapsByUserID returns one page of the swap history of a user, newest first
+func (s *swapServiceImpl) FindSwapsByUserID(ctx context.Context, userID uuid.UUID, page pagination.Page) (*dto.SwapHistory, errs.MessageErr) {
+	result, err := s.swapRepo.FindByUserID(ctx, userID, page)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.SwapStatusResponse, 0, len(result.Items))
+	for _, swap := range result.Items {
+		responses = append(responses, toSwapStatusResponse(swap))
+	}
+	return &dto.SwapHistory{
+		Swaps: responses,
+		CursorPage: dto.CursorPage{
+			NextCursor: result.NextCursor,
+			PrevCursor: result.PrevCursor,
+			Total:      result.Total,
+		},
+	}, nil
+}
+
+func toSwapStatusResponse(swap *entity.Swap) dto.SwapStatusResponse {
+	return dto.SwapStatusResponse{
+		ID:              swap.ID,
+		PublicKey:       swap.PublicKey,
+		InputMint:       swap.InputMint,
+		OutputMint:      swap.OutputMint,
+		InAmount:        swap.InAmount,
+		OutAmount:       swap.OutAmount,
+		MinimumReceived: swap.MinimumReceived,
+		SlippageBps:     swap.SlippageBps,
+		Signature:       swap.Signature,
+		Status:          swap.Status,
+		Slot:            swap.Slot,
+		Error:           swap.Error,
+		SubmittedAt:     swap.SubmittedAt,
+		ConfirmedAt:     swap.ConfirmedAt,
+		CreatedAt:       swap.CreatedAt,
+	}
 }
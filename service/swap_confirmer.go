@@ -0,0 +1,128 @@
+package service
+
+import (
+	"blockchain-scrap/entity"
+	"blockchain-scrap/repository"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// signatureStatusBatchSize is the maximum number of signatures getSignatureStatuses accepts per call
+	signatureStatusBatchSize = 256
+	// swapExpiry is how long a submitted swap may stay unknown to the cluster before it is considered dropped.
+	// A transaction's blockhash is only valid for about 150 blocks, so it can no longer land after this.
+	swapExpiry = 3 * time.Minute
+)
+
+// SwapConfirmer tracks submitted swaps until they reach a final on-chain status
+type SwapConfirmer interface {
+	Run(ctx context.Context)
+}
+
+// swapConfirmer implements SwapConfirmer by polling getSignatureStatuses
+type swapConfirmer struct {
+	swapRepo repository.SwapRepository
+	client   *rpc.Client
+	interval time.Duration
+}
+
+// NewSwapConfirmer creates a SwapConfirmer that checks pending swaps every interval
+func NewSwapConfirmer(swapRepo repository.SwapRepository, client *rpc.Client, interval time.Duration) SwapConfirmer {
+	return &swapConfirmer{swapRepo: swapRepo, client: client, interval: interval}
+}
+
+// Run polls pending swaps until ctx is cancelled
+func (c *swapConfirmer) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.poll(ctx)
+		}
+	}
+}
+
+// poll refreshes the status of one batch of pending swaps
+func (c *swapConfirmer) poll(ctx context.Context) {
+	swaps, err := c.swapRepo.FindPending(ctx, signatureStatusBatchSize)
+	if err != nil || len(swaps) == 0 {
+		return
+	}
+
+	signatures := make([]solana.Signature, 0, len(swaps))
+	pending := make([]*entity.Swap, 0, len(swaps))
+	for _, swap := range swaps {
+		signature, errSig := solana.SignatureFromBase58(*swap.Signature)
+		if errSig != nil {
+			continue
+		}
+		signatures = append(signatures, signature)
+		pending = append(pending, swap)
+	}
+	if len(signatures) == 0 {
+		return
+	}
+
+	result, errStatus := c.client.GetSignatureStatuses(ctx, true, signatures...)
+	if errStatus != nil {
+		log.Printf("swap confirmer: failed to fetch signature statuses: %v", errStatus)
+		return
+	}
+
+	now := time.Now()
+	for i, swap := range pending {
+		var status *rpc.SignatureStatusesResult
+		if i < len(result.Value) {
+			status = result.Value[i]
+		}
+		if !applySignatureStatus(swap, status, now) {
+			continue
+		}
+		if errSave := c.swapRepo.Save(ctx, swap); errSave != nil {
+			log.Printf("swap confirmer: failed to update swap %s: %s", swap.ID, errSave.Message())
+		}
+	}
+}
+
+// applySignatureStatus moves a swap to the status reported by the cluster, returning whether it changed
+func applySignatureStatus(swap *entity.Swap, status *rpc.SignatureStatusesResult, now time.Time) bool {
+	if status == nil {
+		if swap.Status == entity.SwapStatusSubmitted && swap.SubmittedAt != nil && now.Sub(*swap.SubmittedAt) > swapExpiry {
+			swap.Status = entity.SwapStatusExpired
+			return true
+		}
+		return false
+	}
+
+	next := swap.Status
+	switch {
+	case status.Err != nil:
+		next = entity.SwapStatusFailed
+		message := fmt.Sprintf("%v", status.Err)
+		swap.Error = &message
+	case status.ConfirmationStatus == rpc.ConfirmationStatusFinalized:
+		next = entity.SwapStatusFinalized
+	case status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed:
+		next = entity.SwapStatusConfirmed
+	}
+	if next == swap.Status {
+		return false
+	}
+
+	swap.Status = next
+	swap.Slot = status.Slot
+	if swap.ConfirmedAt == nil && next != entity.SwapStatusFailed {
+		swap.ConfirmedAt = &now
+	}
+	return true
+}
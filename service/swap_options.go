@@ -0,0 +1,133 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/errs"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	jupiterQuoteURL = "https://quote-api.jup.ag/v6/quote"
+	jupiterSwapURL  = "https://quote-api.jup.ag/v6/swap"
+
+	defaultSlippageBps = 50
+	maxSlippageBps     = 5000
+	maxRouteAccounts   = 64
+	// maxPrioritizationFeeLamports guards against fat-fingered priority fees (0.01 SOL)
+	maxPrioritizationFeeLamports = 10_000_000
+	// maxComputeUnitPriceMicroLamports caps the unit price so a transaction using the 1.4M compute
+	// unit maximum pays no more than maxPrioritizationFeeLamports
+	maxComputeUnitPriceMicroLamports = maxPrioritizationFeeLamports * 1_000_000 / 1_400_000
+)
+
+// validateSwapOptions checks the optional Jupiter parameters of a swap request
+func validateSwapOptions(req dto.SwapRequest) errs.MessageErr {
+	if req.Amount <= 0 {
+		return errs.NewBadRequest("amount must be greater than 0")
+	}
+	if req.InputMint == req.OutputMint {
+		return errs.NewBadRequest("inputMint and outputMint must be different")
+	}
+	if req.AutoSlippage && req.SlippageBps != nil {
+		return errs.NewBadRequest("slippageBps and autoSlippage cannot be used together")
+	}
+	if req.SlippageBps != nil && (*req.SlippageBps < 0 || *req.SlippageBps > maxSlippageBps) {
+		return errs.NewBadRequest(fmt.Sprintf("slippageBps must be between 0 and %d", maxSlippageBps))
+	}
+	if req.MaxAutoSlippageBps != nil {
+		if !req.AutoSlippage {
+			return errs.NewBadRequest("maxAutoSlippageBps requires autoSlippage")
+		}
+		if *req.MaxAutoSlippageBps <= 0 || *req.MaxAutoSlippageBps > maxSlippageBps {
+			return errs.NewBadRequest(fmt.Sprintf("maxAutoSlippageBps must be between 1 and %d", maxSlippageBps))
+		}
+	}
+	if len(req.Dexes) > 0 && len(req.ExcludeDexes) > 0 {
+		return errs.NewBadRequest("dexes and excludeDexes cannot be used together")
+	}
+	for _, dex := range append(append([]string{}, req.Dexes...), req.ExcludeDexes...) {
+		if strings.TrimSpace(dex) == "" || strings.Contains(dex, ",") {
+			return errs.NewBadRequest("invalid DEX label: " + dex)
+		}
+	}
+	if req.MaxAccounts != nil && (*req.MaxAccounts <= 0 || *req.MaxAccounts > maxRouteAccounts) {
+		return errs.NewBadRequest(fmt.Sprintf("maxAccounts must be between 1 and %d", maxRouteAccounts))
+	}
+	if req.ComputeUnitPriceMicroLamports != nil && req.PrioritizationFeeLamports != nil {
+		return errs.NewBadRequest("computeUnitPriceMicroLamports and prioritizationFeeLamports cannot be used together")
+	}
+	if req.ComputeUnitPriceMicroLamports != nil && (*req.ComputeUnitPriceMicroLamports < 0 || *req.ComputeUnitPriceMicroLamports > maxComputeUnitPriceMicroLamports) {
+		return errs.NewBadRequest(fmt.Sprintf("computeUnitPriceMicroLamports must be between 0 and %d", maxComputeUnitPriceMicroLamports))
+	}
+	if req.PrioritizationFeeLamports != nil && (*req.PrioritizationFeeLamports < 0 || *req.PrioritizationFeeLamports > maxPrioritizationFeeLamports) {
+		return errs.NewBadRequest(fmt.Sprintf("prioritizationFeeLamports must be between 0 and %d", maxPrioritizationFeeLamports))
+	}
+	return nil
+}
+
+// buildQuoteURL builds the Jupiter quote URL for a swap request and an amount in the input token's smallest unit
+func buildQuoteURL(req dto.SwapRequest, amount int64) string {
+	query := url.Values{}
+	query.Set("inputMint", req.InputMint)
+	query.Set("outputMint", req.OutputMint)
+	query.Set("amount", strconv.FormatInt(amount, 10))
+
+	if req.AutoSlippage {
+		query.Set("autoSlippage", "true")
+		if req.MaxAutoSlippageBps != nil {
+			query.Set("maxAutoSlippageBps", strconv.Itoa(*req.MaxAutoSlippageBps))
+		}
+	} else {
+		slippageBps := defaultSlippageBps
+		if req.SlippageBps != nil {
+			slippageBps = *req.SlippageBps
+		}
+		query.Set("slippageBps", strconv.Itoa(slippageBps))
+	}
+
+	if req.OnlyDirectRoutes {
+		query.Set("onlyDirectRoutes", "true")
+	}
+	if len(req.Dexes) > 0 {
+		query.Set("dexes", strings.Join(req.Dexes, ","))
+	}
+	if len(req.ExcludeDexes) > 0 {
+		query.Set("excludeDexes", strings.Join(req.ExcludeDexes, ","))
+	}
+	if req.MaxAccounts != nil {
+		query.Set("maxAccounts", strconv.Itoa(*req.MaxAccounts))
+	}
+
+	return jupiterQuoteURL + "?" + query.Encode()
+}
+
+// buildSwapPayload builds the Jupiter /swap request body for a quote
+func buildSwapPayload(req dto.SwapRequest, quoteResponse map[string]interface{}) map[string]interface{} {
+	payload := map[string]interface{}{
+		"quoteResponse": quoteResponse,
+		"userPublicKey": req.PublicKey,
+		"wrapUnwrapSOL": true,
+	}
+
+	if req.ComputeUnitPriceMicroLamports != nil {
+		payload["computeUnitPriceMicroLamports"] = *req.ComputeUnitPriceMicroLamports
+	}
+	if req.PrioritizationFeeLamports != nil {
+		payload["prioritizationFeeLamports"] = *req.PrioritizationFeeLamports
+	}
+	if req.DynamicComputeUnitLimit {
+		payload["dynamicComputeUnitLimit"] = true
+	}
+	if req.AutoSlippage {
+		dynamicSlippage := map[string]interface{}{}
+		if req.MaxAutoSlippageBps != nil {
+			dynamicSlippage["maxBps"] = *req.MaxAutoSlippageBps
+		}
+		payload["dynamicSlippage"] = dynamicSlippage
+	}
+
+	return payload
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"net/http"
+	"testing"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func validSwapRequest() dto.SwapRequest {
+	return dto.SwapRequest{
+		PublicKey:  "wallet",
+		InputMint:  "So11111111111111111111111111111111111111112",
+		OutputMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		Amount:     1,
+	}
+}
+
+func TestValidateSwapOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(req *dto.SwapRequest)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(req *dto.SwapRequest) {}},
+		{name: "zero amount", modify: func(req *dto.SwapRequest) { req.Amount = 0 }, wantErr: true},
+		{name: "same mints", modify: func(req *dto.SwapRequest) { req.OutputMint = req.InputMint }, wantErr: true},
+		{name: "slippage at max", modify: func(req *dto.SwapRequest) { req.SlippageBps = intPtr(maxSlippageBps) }},
+		{name: "slippage above max", modify: func(req *dto.SwapRequest) { req.SlippageBps = intPtr(maxSlippageBps + 1) }, wantErr: true},
+		{name: "negative slippage", modify: func(req *dto.SwapRequest) { req.SlippageBps = intPtr(-1) }, wantErr: true},
+		{name: "auto slippage with bound", modify: func(req *dto.SwapRequest) {
+			req.AutoSlippage = true
+			req.MaxAutoSlippageBps = intPtr(300)
+		}},
+		{name: "auto slippage with explicit slippage", modify: func(req *dto.SwapRequest) {
+			req.AutoSlippage = true
+			req.SlippageBps = intPtr(100)
+		}, wantErr: true},
+		{name: "auto slippage bound without auto slippage", modify: func(req *dto.SwapRequest) {
+			req.MaxAutoSlippageBps = intPtr(300)
+		}, wantErr: true},
+		{name: "zero auto slippage bound", modify: func(req *dto.SwapRequest) {
+			req.AutoSlippage = true
+			req.MaxAutoSlippageBps = intPtr(0)
+		}, wantErr: true},
+		{name: "dexes and excluded dexes", modify: func(req *dto.SwapRequest) {
+			req.Dexes = []string{"Raydium"}
+			req.ExcludeDexes = []string{"Orca"}
+		}, wantErr: true},
+		{name: "blank dex label", modify: func(req *dto.SwapRequest) { req.Dexes = []string{" "} }, wantErr: true},
+		{name: "dex label with comma", modify: func(req *dto.SwapRequest) { req.ExcludeDexes = []string{"Orca,Raydium"} }, wantErr: true},
+		{name: "max accounts at limit", modify: func(req *dto.SwapRequest) { req.MaxAccounts = intPtr(maxRouteAccounts) }},
+		{name: "max accounts above limit", modify: func(req *dto.SwapRequest) { req.MaxAccounts = intPtr(maxRouteAccounts + 1) }, wantErr: true},
+		{name: "compute unit price and priority fee", modify: func(req *dto.SwapRequest) {
+			req.ComputeUnitPriceMicroLamports = int64Ptr(1_000)
+			req.PrioritizationFeeLamports = int64Ptr(1_000)
+		}, wantErr: true},
+		{name: "compute unit price at max", modify: func(req *dto.SwapRequest) {
+			req.ComputeUnitPriceMicroLamports = int64Ptr(maxComputeUnitPriceMicroLamports)
+		}},
+		{name: "compute unit price above max", modify: func(req *dto.SwapRequest) {
+			req.ComputeUnitPriceMicroLamports = int64Ptr(maxComputeUnitPriceMicroLamports + 1)
+		}, wantErr: true},
+		{name: "negative compute unit price", modify: func(req *dto.SwapRequest) { req.ComputeUnitPriceMicroLamports = int64Ptr(-1) }, wantErr: true},
+		{name: "priority fee at max", modify: func(req *dto.SwapRequest) { req.PrioritizationFeeLamports = int64Ptr(maxPrioritizationFeeLamports) }},
+		{name: "priority fee above max", modify: func(req *dto.SwapRequest) {
+			req.PrioritizationFeeLamports = int64Ptr(maxPrioritizationFeeLamports + 1)
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validSwapRequest()
+			tt.modify(&req)
+
+			err := validateSwapOptions(req)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err.Message())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if err.StatusCode() != http.StatusBadRequest {
+				t.Errorf("StatusCode = %d, want %d", err.StatusCode(), http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// quoteTTL is how long a Jupiter quote is presented as valid; routes go stale within a few slots
+	quoteTTL = 30 * time.Second
+
+	mediumPriceImpactPct = 1.0
+	highPriceImpactPct   = 5.0
+)
+
+// applyQuoteDetails fills the route plan, price impact, slippage and expiry of a quote into the response.
+// tokenMap must hold the metadata of every known mint of the route.
+func applyQuoteDetails(response *dto.GetCurrencySwapResponse, quote dto.QuoteResponse, tokenMap map[string]*entity.Token, now time.Time) {
+	response.SlippageBps = quote.SlippageBps
+	response.ContextSlot = quote.ContextSlot
+	response.QuotedAt = now
+	response.QuoteExpiresAt = now.Add(quoteTTL)
+
+	if impact, err := strconv.ParseFloat(quote.PriceImpactPct, 64); err == nil {
+		// Jupiter reports the impact as a fraction
+		response.PriceImpactPct = roundTo(impact*100, 4)
+	}
+	response.PriceImpactLevel = priceImpactLevel(response.PriceImpactPct)
+
+	response.MinimumReceived = toUIAmount(quote.OtherAmountThreshold, quote.OutputMint, tokenMap)
+	if response.InAmount > 0 {
+		response.EffectivePrice = response.OutAmount / response.InAmount
+	}
+
+	response.Route = make([]dto.RouteHop, 0, len(quote.RoutePlan))
+	for _, entry := range quote.RoutePlan {
+		info := entry.SwapInfo
+		response.Route = append(response.Route, dto.RouteHop{
+			Percent:     entry.Percent,
+			Label:       info.Label,
+			AmmKey:      info.AmmKey,
+			InputMint:   info.InputMint,
+			OutputMint:  info.OutputMint,
+			InAmount:    info.InAmount,
+			InDecimals:  mintDecimals(info.InputMint, tokenMap),
+			OutAmount:   info.OutAmount,
+			OutDecimals: mintDecimals(info.OutputMint, tokenMap),
+			FeeAmount:   info.FeeAmount,
+			FeeMint:     info.FeeMint,
+			FeeDecimals: mintDecimals(info.FeeMint, tokenMap),
+		})
+	}
+}
+
+// routeMints returns every distinct mint referenced by a quote's route plan
+func routeMints(quote dto.QuoteResponse) []string {
+	seen := make(map[string]bool)
+	mints := []string{}
+	for _, entry := range quote.RoutePlan {
+		for _, mint := range []string{entry.SwapInfo.InputMint, entry.SwapInfo.OutputMint, entry.SwapInfo.FeeMint} {
+			if mint != "" && !seen[mint] {
+				seen[mint] = true
+				mints = append(mints, mint)
+			}
+		}
+	}
+	return mints
+}
+
+// toUIAmount converts a raw amount to UI units using the mint's decimals, leaving it raw when they are unknown
+func toUIAmount(raw, mint string, tokenMap map[string]*entity.Token) float64 {
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0
+	}
+	if token, ok := tokenMap[mint]; ok && token.Decimals > 0 {
+		return amount / math.Pow10(token.Decimals)
+	}
+	return amount
+}
+
+// mintDecimals returns the decimals of a known mint, nil when the mint is not in tokenMap
+func mintDecimals(mint string, tokenMap map[string]*entity.Token) *int {
+	token, ok := tokenMap[mint]
+	if !ok {
+		return nil
+	}
+	decimals := token.Decimals
+	return &decimals
+}
+
+// priceImpactLevel classifies a price impact percentage for UI warnings
+func priceImpactLevel(impactPct float64) string {
+	switch {
+	case impactPct >= highPriceImpactPct:
+		return "high"
+	case impactPct >= mediumPriceImpactPct:
+		return "medium"
+	default:
+		return "low"
+	}
+}
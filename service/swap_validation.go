@@ -0,0 +1,102 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/pkg/config"
+	"blockchain-scrap/pkg/errs"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// jupiterProgramID is the Jupiter v6 aggregator program
+var jupiterProgramID = solana.MustPublicKeyFromBase58("JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4")
+
+// swapAllowedPrograms returns the programs a relayed swap transaction may invoke: Jupiter, the
+// programs its swap transactions set up accounts and fees with, and any listed in SWAP_ALLOWED_PROGRAMS
+// (for instance wallet guard programs that inject assertion instructions).
+func swapAllowedPrograms() map[solana.PublicKey]bool {
+	allowed := map[solana.PublicKey]bool{
+		jupiterProgramID:                          true,
+		solana.ComputeBudget:                      true,
+		solana.SystemProgramID:                    true,
+		solana.TokenProgramID:                     true,
+		solana.Token2022ProgramID:                 true,
+		solana.SPLAssociatedTokenAccountProgramID: true,
+	}
+	for _, program := range config.List("SWAP_ALLOWED_PROGRAMS") {
+		if key, err := solana.PublicKeyFromBase58(program); err == nil {
+			allowed[key] = true
+		}
+	}
+	return allowed
+}
+
+// validateSignedTransaction checks a signed swap transaction before it is relayed.
+// expectedPayer is the public key that requested the quote and must be the fee payer.
+func validateSignedTransaction(tx *solana.Transaction, expectedPayer string, allowed map[solana.PublicKey]bool) errs.MessageErr {
+	if len(tx.Message.AccountKeys) == 0 || len(tx.Signatures) == 0 {
+		return errs.NewBadRequest("transaction has no signer")
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		return errs.NewBadRequest(fmt.Sprintf("invalid transaction signature: %v", err))
+	}
+
+	feePayer := tx.Message.AccountKeys[0]
+	if feePayer.String() != expectedPayer {
+		return errs.NewBadRequest("transaction fee payer does not match the public key of the swap")
+	}
+
+	programs, err := tx.GetProgramIDs()
+	if err != nil {
+		return errs.NewBadRequest(fmt.Sprintf("invalid transaction instructions: %v", err))
+	}
+	invokesJupiter := false
+	for _, program := range programs {
+		if !allowed[program] {
+			return errs.NewBadRequest(fmt.Sprintf("transaction invokes unsupported program %s", program))
+		}
+		if program.Equals(jupiterProgramID) {
+			invokesJupiter = true
+		}
+	}
+	if !invokesJupiter {
+		return errs.NewBadRequest("transaction is not a Jupiter swap")
+	}
+
+	return nil
+}
+
+// toSimulationResult converts an RPC simulation result for the client
+func toSimulationResult(result *rpc.SimulateTransactionResult) *dto.SimulationResult {
+	return &dto.SimulationResult{
+		Err:           result.Err,
+		Logs:          result.Logs,
+		UnitsConsumed: result.UnitsConsumed,
+	}
+}
+
+// preflightFailure extracts the simulation details of a transaction rejected by the RPC node's preflight check
+func preflightFailure(err error) (string, *dto.SimulationResult) {
+	var rpcErr *jsonrpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		return err.Error(), nil
+	}
+
+	data, ok := rpcErr.Data.(map[string]interface{})
+	if !ok {
+		return rpcErr.Message, nil
+	}
+	simulation := &dto.SimulationResult{Err: data["err"]}
+	if logs, ok := data["logs"].([]interface{}); ok {
+		for _, line := range logs {
+			if text, ok := line.(string); ok {
+				simulation.Logs = append(simulation.Logs, text)
+			}
+		}
+	}
+	return rpcErr.Message, simulation
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// newTestSwapTransaction builds a transaction paid by payer that invokes programs, signed by signer when set
+func newTestSwapTransaction(t *testing.T, payer solana.PrivateKey, signer *solana.PrivateKey, programs ...solana.PublicKey) *solana.Transaction {
+	t.Helper()
+
+	instructions := make([]solana.Instruction, 0, len(programs))
+	for _, program := range programs {
+		instructions = append(instructions, solana.NewInstruction(program,
+			solana.AccountMetaSlice{solana.Meta(payer.PublicKey()).WRITE().SIGNER()}, []byte{1}))
+	}
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(payer.PublicKey()))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if signer != nil {
+		if _, err := tx.Sign(func(solana.PublicKey) *solana.PrivateKey { return signer }); err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+	}
+	return tx
+}
+
+func TestValidateSignedTransaction(t *testing.T) {
+	payer := solana.NewWallet().PrivateKey
+	other := solana.NewWallet().PrivateKey
+	guardProgram := solana.NewWallet().PublicKey()
+
+	allowed := map[solana.PublicKey]bool{
+		jupiterProgramID:     true,
+		solana.ComputeBudget: true,
+	}
+	allowedWithGuard := map[solana.PublicKey]bool{
+		jupiterProgramID:     true,
+		solana.ComputeBudget: true,
+		guardProgram:         true,
+	}
+
+	tests := []struct {
+		name          string
+		tx            *solana.Transaction
+		expectedPayer string
+		allowed       map[solana.PublicKey]bool
+		wantErr       bool
+	}{
+		{
+			name:          "jupiter swap",
+			tx:            newTestSwapTransaction(t, payer, &payer, solana.ComputeBudget, jupiterProgramID),
+			expectedPayer: payer.PublicKey().String(),
+			allowed:       allowed,
+		},
+		{
+			name:          "unsigned",
+			tx:            newTestSwapTransaction(t, payer, nil, jupiterProgramID),
+			expectedPayer: payer.PublicKey().String(),
+			allowed:       allowed,
+			wantErr:       true,
+		},
+		{
+			name:          "signed by another key",
+			tx:            newTestSwapTransaction(t, payer, &other, jupiterProgramID),
+			expectedPayer: payer.PublicKey().String(),
+			allowed:       allowed,
+			wantErr:       true,
+		},
+		{
+			name:          "fee payer differs from the swap public key",
+			tx:            newTestSwapTransaction(t, other, &other, jupiterProgramID),
+			expectedPayer: payer.PublicKey().String(),
+			allowed:       allowed,
+			wantErr:       true,
+		},
+		{
+			name:          "unsupported program",
+			tx:            newTestSwapTransaction(t, payer, &payer, jupiterProgramID, guardProgram),
+			expectedPayer: payer.PublicKey().String(),
+			allowed:       allowed,
+			wantErr:       true,
+		},
+		{
+			name:          "configured extra program",
+			tx:            newTestSwapTransaction(t, payer, &payer, guardProgram, jupiterProgramID),
+			expectedPayer: payer.PublicKey().String(),
+			allowed:       allowedWithGuard,
+		},
+		{
+			name:          "no jupiter instruction",
+			tx:            newTestSwapTransaction(t, payer, &payer, solana.ComputeBudget),
+			expectedPayer: payer.PublicKey().String(),
+			allowed:       allowed,
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSignedTransaction(tt.tx, tt.expectedPayer, tt.allowed)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err.Message())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if err.StatusCode() != http.StatusBadRequest {
+				t.Errorf("StatusCode = %d, want %d", err.StatusCode(), http.StatusBadRequest)
+			}
+		})
+	}
+}
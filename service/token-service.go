@@ -4,32 +4,39 @@ import (
 	"blockchain-scrap/dto"
 	"blockchain-scrap/entity"
 	"blockchain-scrap/pkg/errs"
-	httprequest "blockchain-scrap/pkg/http-request"
+	"blockchain-scrap/pkg/pagination"
 	"blockchain-scrap/repository"
-	"encoding/json"
+	"context"
+	"math/big"
 	"strconv"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 )
 
 type TokenService interface {
-	GetAllTokens(limit, offset int, search string) (*dto.TokenResponse, errs.MessageErr)
+	GetAllTokens(filter dto.TokenFilter, page pagination.Page) (*dto.TokenResponse, errs.MessageErr)
 	FetchAccountInfo(address string) ([]*dto.TokenAccountsResponse, errs.MessageErr)
+	GetPortfolio(ctx context.Context, address string) (*dto.PortfolioResponse, errs.MessageErr)
 }
 
 type tokenService struct {
-	repo repository.TokenRepository
+	repo   repository.TokenRepository
+	client *rpc.Client
+	coins  CoinMarketSource
+	prices TokenPriceSource
 }
 
-func NewTokenService(r repository.TokenRepository) TokenService {
-	return &tokenService{r}
+func NewTokenService(r repository.TokenRepository, client *rpc.Client, coins CoinMarketSource, prices TokenPriceSource) TokenService {
+	return &tokenService{repo: r, client: client, coins: coins, prices: prices}
 }
 
-func (s *tokenService) GetAllTokens(limit, offset int, search string) (*dto.TokenResponse, errs.MessageErr) {
-	tokens, count, err := s.repo.GetAll(limit, offset, search)
+func (s *tokenService) GetAllTokens(filter dto.TokenFilter, page pagination.Page) (*dto.TokenResponse, errs.MessageErr) {
+	result, err := s.repo.GetAll(filter, page)
 	if err != nil {
-		return nil, errs.NewInternalServerError(err.Error())
+		return nil, err
 	}
+	tokens := result.Items
 
 	tokenDTOs := make([]*dto.TokenDTO, len(tokens))
 	for i, token := range tokens {
@@ -52,7 +59,11 @@ func (s *tokenService) GetAllTokens(limit, offset int, search string) (*dto.Toke
 
 	return &dto.TokenResponse{
 		Tokens: tokenDTOs,
-		Total:  count,
+		CursorPage: dto.CursorPage{
+			NextCursor: result.NextCursor,
+			PrevCursor: result.PrevCursor,
+			Total:      result.Total,
+		},
 	}, nil
 }
 
@@ -62,94 +73,65 @@ func (s *tokenService) FetchAccountInfo(address string) ([]*dto.TokenAccountsRes
 		return nil, errs.NewBadRequest("Invalid Solana address")
 	}
 
-	url := "https://api.mainnet-beta.solana.com"
+	ctx := context.Background()
 
 	type resultTokenAccounts struct {
-		data *dto.TokenAccountsApiResponse
-		err  errs.MessageErr
+		program string
+		data    *dto.TokenAccountsApiResponse
+		err     errs.MessageErr
 	}
 	type resultNativeSol struct {
 		data *dto.TokenAccountsSolanaNativeApiResponse
 		err  errs.MessageErr
 	}
 
-	tokenCh := make(chan resultTokenAccounts)
-	nativeCh := make(chan resultNativeSol)
+	tokenPrograms := []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID}
+	tokenCh := make(chan resultTokenAccounts, len(tokenPrograms))
+	nativeCh := make(chan resultNativeSol, 1)
 
-	// Goroutine untuk getTokenAccountsByOwner
-	go func() {
-		payload := map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      "1",
-			"method":  "getTokenAccountsByOwner",
-			"params": []interface{}{
+	// Goroutine untuk getTokenAccountsByOwner, satu per token program
+	for _, programID := range tokenPrograms {
+		go func(programID solana.PublicKey) {
+			params := []interface{}{
 				address,
-				map[string]string{"programId": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"},
+				map[string]string{"programId": programID.String()},
 				map[string]string{"encoding": "jsonParsed"},
-			},
-		}
-		payloadBuffer, err := json.Marshal(payload)
-		if err != nil {
-			tokenCh <- resultTokenAccounts{nil, errs.NewInternalServerError(err.Error())}
-			return
-		}
-		body, errRequest := httprequest.ProcessJSONRequest("POST", url, payloadBuffer, nil)
-		if errRequest != nil {
-			tokenCh <- resultTokenAccounts{nil, errRequest}
-			return
-		}
-		var result dto.TokenAccountsApiResponse
-		err = json.Unmarshal(body, &result)
-		if err != nil {
-			tokenCh <- resultTokenAccounts{nil, errs.NewInternalServerError(err.Error())}
-			return
-		}
-		tokenCh <- resultTokenAccounts{&result, nil}
-	}()
+			}
+			var result dto.TokenAccountsApiResponse
+			if err := s.client.RPCCallForInto(ctx, &result.Result, "getTokenAccountsByOwner", params); err != nil {
+				tokenCh <- resultTokenAccounts{programID.String(), nil, errs.NewInternalServerError(err.Error())}
+				return
+			}
+			tokenCh <- resultTokenAccounts{programID.String(), &result, nil}
+		}(programID)
+	}
 
 	// Goroutine untuk getBalance
 	go func() {
-		payload := map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      "1",
-			"method":  "getBalance",
-			"params":  []interface{}{address},
-		}
-		payloadBuffer, err := json.Marshal(payload)
-		if err != nil {
-			nativeCh <- resultNativeSol{nil, errs.NewInternalServerError(err.Error())}
-			return
-		}
-		body, errRequest := httprequest.ProcessJSONRequest("POST", url, payloadBuffer, nil)
-		if errRequest != nil {
-			nativeCh <- resultNativeSol{nil, errRequest}
-			return
-		}
 		var result dto.TokenAccountsSolanaNativeApiResponse
-		err = json.Unmarshal(body, &result)
-		if err != nil {
+		if err := s.client.RPCCallForInto(ctx, &result.Result, "getBalance", []interface{}{address}); err != nil {
 			nativeCh <- resultNativeSol{nil, errs.NewInternalServerError(err.Error())}
 			return
 		}
 		nativeCh <- resultNativeSol{&result, nil}
 	}()
 
-	// Ambil hasil dari kedua goroutine
-	var (
-		tokenResult  resultTokenAccounts
-		nativeResult resultNativeSol
-	)
-	for i := 0; i < 2; i++ {
+	// Ambil hasil dari semua goroutine
+	tokenResults := make(map[string]resultTokenAccounts, len(tokenPrograms))
+	var nativeResult resultNativeSol
+	for i := 0; i < len(tokenPrograms)+1; i++ {
 		select {
 		case tr := <-tokenCh:
-			tokenResult = tr
+			tokenResults[tr.program] = tr
 		case nr := <-nativeCh:
 			nativeResult = nr
 		}
 	}
 
-	if tokenResult.err != nil {
-		return nil, tokenResult.err
+	for _, programID := range tokenPrograms {
+		if tokenResults[programID.String()].err != nil {
+			return nil, tokenResults[programID.String()].err
+		}
 	}
 	if nativeResult.err != nil {
 		return nil, nativeResult.err
@@ -162,17 +144,38 @@ func (s *tokenService) FetchAccountInfo(address string) ([]*dto.TokenAccountsRes
 	tokens = append(tokens, &dto.TokenAccountsResponse{
 		Address: solanaNativeAddress,
 		Amount:  strconv.FormatFloat(nativeResult.data.Result.Value, 'f', -1, 64),
+		Program: "native",
 	})
 	mintAddresses = append(mintAddresses, solanaNativeAddress)
 
-	for _, acc := range tokenResult.data.Result.Value {
-		mint := acc.Account.Data.Parsed.Info.Mint
-		amount := acc.Account.Data.Parsed.Info.TokenAmount.Amount
-		mintAddresses = append(mintAddresses, mint)
-		tokens = append(tokens, &dto.TokenAccountsResponse{
-			Address: mint,
-			Amount:  amount,
-		})
+	// Satu mint dapat tersebar di beberapa token account, saldonya dijumlahkan per mint
+	byMint := make(map[string]*dto.TokenAccountsResponse)
+	var token2022Mints []string
+	for _, programID := range tokenPrograms {
+		for _, acc := range tokenResults[programID.String()].data.Result.Value {
+			mint := acc.Account.Data.Parsed.Info.Mint
+			amount := acc.Account.Data.Parsed.Info.TokenAmount.Amount
+			if existing, ok := byMint[mint]; ok {
+				existing.Amount = addRawAmounts(existing.Amount, amount)
+				continue
+			}
+			token := &dto.TokenAccountsResponse{
+				Address:  mint,
+				Amount:   amount,
+				Decimals: acc.Account.Data.Parsed.Info.TokenAmount.Decimals,
+				Program:  acc.Account.Data.Program,
+			}
+			byMint[mint] = token
+			mintAddresses = append(mintAddresses, mint)
+			tokens = append(tokens, token)
+			if programID.Equals(solana.Token2022ProgramID) {
+				token2022Mints = append(token2022Mints, mint)
+			}
+		}
+	}
+
+	if len(token2022Mints) > 0 {
+		s.applyMintExtensions(ctx, token2022Mints, byMint)
 	}
 
 	tokenEntities, err := s.repo.FindByAddress(mintAddresses)
@@ -189,7 +192,24 @@ func (s *tokenService) FetchAccountInfo(address string) ([]*dto.TokenAccountsRes
 			token.Name = ent.Name
 			token.Symbol = ent.Symbol
 			token.Decimals = ent.Decimals
+			token.Extensions = ent.Extensions
+			if token.PermanentDelegate == nil {
+				token.PermanentDelegate = ent.PermanentDelegate
+			}
 		}
 	}
 	return tokens, nil
 }
+
+// addRawAmounts sums two raw u64 token amounts without losing precision
+func addRawAmounts(a, b string) string {
+	x, okX := new(big.Int).SetString(a, 10)
+	y, okY := new(big.Int).SetString(b, 10)
+	if !okX {
+		return b
+	}
+	if !okY {
+		return a
+	}
+	return x.Add(x, y).String()
+}
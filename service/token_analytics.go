@@ -0,0 +1,347 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/errs"
+	"context"
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// topWalletsCount is the number of largest holders summed into TopWallets
+	topWalletsCount = 10
+	// signaturePageLimit is the page size used when walking a mint's signature history
+	signaturePageLimit = 1000
+	// maxSignaturePages bounds how far back the mint history is walked to find its creation
+	maxSignaturePages = 5
+	// earlyTransactionCount is the number of earliest mint transactions inspected for dev and sniper activity
+	earlyTransactionCount = 25
+	// earlyTransactionWorkers is the number of early transactions fetched at once
+	earlyTransactionWorkers = 5
+	// earlyActivityTimeout bounds the signature walk and transaction fetches of earlyActivity
+	earlyActivityTimeout = 10 * time.Second
+	// sniperSlotWindow is the number of slots after creation in which third-party buys count as snipes
+	sniperSlotWindow = 10
+)
+
+// TokenAnalyzer defines the contract for on-chain holder analytics of a token
+type TokenAnalyzer interface {
+	Analyze(ctx context.Context, mint string, token *entity.Token) (*dto.TokenAnalytics, errs.MessageErr)
+}
+
+// solanaTokenAnalyzer implements TokenAnalyzer using the Solana JSON RPC API
+type solanaTokenAnalyzer struct {
+	client *rpc.Client
+}
+
+// NewSolanaTokenAnalyzer creates a TokenAnalyzer backed by the given Solana RPC client
+func NewSolanaTokenAnalyzer(client *rpc.Client) TokenAnalyzer {
+	return &solanaTokenAnalyzer{client: client}
+}
+
+// earlyActivity is what the earliest transactions of a mint reveal about its creator and snipers
+type earlyActivity struct {
+	dev         solana.PublicKey
+	devBalances []float64
+	snipers     map[solana.PublicKey]bool
+}
+
+// Analyze computes holder concentration and dev/sniper activity for a mint.
+//
+// TopHolder and TopWallets are the percentages of supply held by the largest owner and
+// the largest topWalletsCount owners. The dev wallet is the fee payer of the mint's first
+// readable transaction, falling back to the mint authority when the creation is too far back
+// to be reached or none of the earliest transactions can be read. SniperBot is the percentage
+// of supply still held by wallets that bought within sniperSlotWindow slots of creation.
+// DevSold and DevBuyback compare the dev balances seen in the earliest transactions against
+// the current dev balance.
+func (a *solanaTokenAnalyzer) Analyze(ctx context.Context, mint string, token *entity.Token) (*dto.TokenAnalytics, errs.MessageErr) {
+	mintKey, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return nil, errs.NewBadRequest("Invalid Solana address")
+	}
+
+	supplyResult, err := a.client.GetTokenSupply(ctx, mintKey, rpc.CommitmentConfirmed)
+	if err != nil || supplyResult.Value == nil {
+		return nil, errs.NewInternalServerError("Failed to fetch token supply")
+	}
+	supply := parseRawAmount(supplyResult.Value.Amount)
+	if supply <= 0 {
+		return &dto.TokenAnalytics{}, nil
+	}
+
+	holdings, errHoldings := a.largestOwnerHoldings(ctx, mintKey)
+	if errHoldings != nil {
+		return nil, errHoldings
+	}
+
+	analytics := &dto.TokenAnalytics{}
+	owners := make([]solana.PublicKey, 0, len(holdings))
+	for owner := range holdings {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool { return holdings[owners[i]] > holdings[owners[j]] })
+
+	for i, owner := range owners {
+		share := holdings[owner] / supply * 100
+		if i == 0 {
+			analytics.TopHolder = roundTo(share, 4)
+		}
+		if i < topWalletsCount {
+			analytics.TopWallets += share
+		}
+	}
+	analytics.TopWallets = roundTo(analytics.TopWallets, 4)
+
+	activity := a.earlyActivity(ctx, mintKey)
+	if activity == nil {
+		activity = &earlyActivity{snipers: map[solana.PublicKey]bool{}}
+		if token != nil && token.MintAuthority != nil {
+			if authority, err := solana.PublicKeyFromBase58(*token.MintAuthority); err == nil {
+				activity.dev = authority
+			}
+		}
+	}
+
+	var sniperHoldings float64
+	for owner := range activity.snipers {
+		sniperHoldings += holdings[owner]
+	}
+	analytics.SniperBot = roundTo(sniperHoldings/supply*100, 4)
+	analytics.SniperWallets = len(activity.snipers)
+
+	if activity.dev.IsZero() {
+		return analytics, nil
+	}
+	analytics.DevWallet = activity.dev.String()
+
+	currentBalance, ok := holdings[activity.dev]
+	if !ok {
+		currentBalance, err = a.ownerBalance(ctx, activity.dev, mintKey)
+		if err != nil {
+			return analytics, nil
+		}
+	}
+	analytics.DevSold, analytics.DevBuyback = devTradeFlags(activity.devBalances, currentBalance)
+
+	return analytics, nil
+}
+
+// largestOwnerHoldings returns the raw balances of the largest token accounts grouped by wallet owner
+func (a *solanaTokenAnalyzer) largestOwnerHoldings(ctx context.Context, mint solana.PublicKey) (map[solana.PublicKey]float64, errs.MessageErr) {
+	largest, err := a.client.GetTokenLargestAccounts(ctx, mint, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch token largest accounts")
+	}
+
+	holdings := make(map[solana.PublicKey]float64)
+	if len(largest.Value) == 0 {
+		return holdings, nil
+	}
+
+	accounts := make([]solana.PublicKey, len(largest.Value))
+	for i, account := range largest.Value {
+		accounts[i] = account.Address
+	}
+
+	infos, err := a.client.GetMultipleAccounts(ctx, accounts...)
+	if err != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch token account owners")
+	}
+
+	for i, info := range infos.Value {
+		owner := accounts[i]
+		if info != nil && info.Data != nil {
+			// SPL Token and Token-2022 accounts share the layout mint[0:32] owner[32:64]
+			if data := info.Data.GetBinary(); len(data) >= 64 {
+				owner = solana.PublicKeyFromBytes(data[32:64])
+			}
+		}
+		holdings[owner] += parseRawAmount(largest.Value[i].Amount)
+	}
+
+	return holdings, nil
+}
+
+// earlyActivity inspects the earliest transactions of a mint, returning nil when its creation cannot be
+// reached within earlyActivityTimeout or none of its earliest transactions can be read
+func (a *solanaTokenAnalyzer) earlyActivity(ctx context.Context, mint solana.PublicKey) *earlyActivity {
+	ctx, cancel := context.WithTimeout(ctx, earlyActivityTimeout)
+	defer cancel()
+
+	var (
+		signatures []*rpc.TransactionSignature
+		before     solana.Signature
+		limit      = signaturePageLimit
+		reached    bool
+	)
+
+	for page := 0; page < maxSignaturePages; page++ {
+		result, err := a.client.GetSignaturesForAddressWithOpts(ctx, mint, &rpc.GetSignaturesForAddressOpts{
+			Limit:      &limit,
+			Before:     before,
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		if err != nil {
+			return nil
+		}
+		signatures = append(signatures, result...)
+		if len(result) < limit {
+			reached = true
+			break
+		}
+		before = result[len(result)-1].Signature
+	}
+
+	if !reached || len(signatures) == 0 {
+		return nil
+	}
+
+	// Signatures are returned newest first
+	early := make([]*rpc.TransactionSignature, 0, earlyTransactionCount)
+	for i := len(signatures) - 1; i >= 0 && len(early) < earlyTransactionCount; i-- {
+		if signatures[i].Err == nil {
+			early = append(early, signatures[i])
+		}
+	}
+
+	activity := &earlyActivity{snipers: map[solana.PublicKey]bool{}}
+	var creationSlot uint64
+
+	// Transactions are fetched concurrently but inspected oldest first, the dev is the first fee payer
+	for _, result := range a.fetchTransactions(ctx, early) {
+		if result == nil || result.Transaction == nil || result.Meta == nil {
+			continue
+		}
+		tx, err := result.Transaction.GetTransaction()
+		if err != nil || len(tx.Message.AccountKeys) == 0 {
+			continue
+		}
+
+		// The first transaction that can be read stands in for the creation when earlier ones fail
+		feePayer := tx.Message.AccountKeys[0]
+		if activity.dev.IsZero() {
+			activity.dev = feePayer
+			creationSlot = result.Slot
+		}
+
+		if feePayer.Equals(activity.dev) {
+			activity.devBalances = append(activity.devBalances, ownerTokenBalance(result.Meta.PostTokenBalances, activity.dev, mint))
+			continue
+		}
+
+		if result.Slot-creationSlot <= sniperSlotWindow {
+			pre := ownerTokenBalance(result.Meta.PreTokenBalances, feePayer, mint)
+			post := ownerTokenBalance(result.Meta.PostTokenBalances, feePayer, mint)
+			if post > pre {
+				activity.snipers[feePayer] = true
+			}
+		}
+	}
+
+	if activity.dev.IsZero() {
+		return nil
+	}
+	return activity
+}
+
+// fetchTransactions fetches transactions with earlyTransactionWorkers concurrent requests, keeping the
+// order of signatures. Transactions that cannot be fetched are nil.
+func (a *solanaTokenAnalyzer) fetchTransactions(ctx context.Context, signatures []*rpc.TransactionSignature) []*rpc.GetTransactionResult {
+	results := make([]*rpc.GetTransactionResult, len(signatures))
+	maxVersion := uint64(0)
+	slots := make(chan struct{}, earlyTransactionWorkers)
+	var wg sync.WaitGroup
+
+	for i, signature := range signatures {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, signature solana.Signature) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, err := a.client.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+				Encoding:                       solana.EncodingBase64,
+				Commitment:                     rpc.CommitmentConfirmed,
+				MaxSupportedTransactionVersion: &maxVersion,
+			})
+			if err == nil {
+				results[i] = result
+			}
+		}(i, signature.Signature)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// ownerBalance returns the total raw balance of a mint held by an owner across all of its token accounts
+func (a *solanaTokenAnalyzer) ownerBalance(ctx context.Context, owner, mint solana.PublicKey) (float64, error) {
+	result, err := a.client.GetTokenAccountsByOwner(ctx, owner, &rpc.GetTokenAccountsConfig{Mint: &mint}, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var balance float64
+	for _, account := range result.Value {
+		if account.Account.Data == nil {
+			continue
+		}
+		// The raw amount is a little-endian u64 right after the mint and owner
+		if data := account.Account.Data.GetBinary(); len(data) >= 72 {
+			balance += float64(binary.LittleEndian.Uint64(data[64:72]))
+		}
+	}
+	return balance, nil
+}
+
+// ownerTokenBalance sums the raw balances of a mint owned by owner in a transaction's token balances
+func ownerTokenBalance(balances []rpc.TokenBalance, owner, mint solana.PublicKey) float64 {
+	var total float64
+	for _, balance := range balances {
+		if balance.Owner == nil || !balance.Owner.Equals(owner) || !balance.Mint.Equals(mint) || balance.UiTokenAmount == nil {
+			continue
+		}
+		total += parseRawAmount(balance.UiTokenAmount.Amount)
+	}
+	return total
+}
+
+// devTradeFlags reports whether the dev sold below its observed peak and whether it bought back after selling
+func devTradeFlags(observed []float64, current float64) (sold, buyback bool) {
+	balances := append(append([]float64{}, observed...), current)
+
+	var peak float64
+	lowAfterSale := -1.0
+	for _, balance := range balances {
+		if balance < peak {
+			sold = true
+			if lowAfterSale < 0 || balance < lowAfterSale {
+				lowAfterSale = balance
+			}
+		} else if sold && balance > lowAfterSale {
+			buyback = true
+		}
+		if balance > peak {
+			peak = balance
+		}
+	}
+	return sold, buyback
+}
+
+// parseRawAmount converts a raw token amount string into a float, returning 0 when it is malformed
+func parseRawAmount(amount string) float64 {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
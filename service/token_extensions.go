@@ -0,0 +1,101 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"context"
+	"encoding/json"
+	"math"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// transferFeeState is one transfer fee of the Token-2022 transferFeeConfig mint extension
+type transferFeeState struct {
+	Epoch                  uint64 `json:"epoch"`
+	MaximumFee             uint64 `json:"maximumFee"`
+	TransferFeeBasisPoints int    `json:"transferFeeBasisPoints"`
+}
+
+// transferFeeConfigState is the jsonParsed state of the Token-2022 transferFeeConfig mint extension.
+// A fee update only takes effect from NewerTransferFee.Epoch, OlderTransferFee applies until then.
+type transferFeeConfigState struct {
+	OlderTransferFee transferFeeState `json:"olderTransferFee"`
+	NewerTransferFee transferFeeState `json:"newerTransferFee"`
+}
+
+// activeFee returns the transfer fee in effect at epoch
+func (c transferFeeConfigState) activeFee(epoch uint64) transferFeeState {
+	if epoch < c.NewerTransferFee.Epoch {
+		return c.OlderTransferFee
+	}
+	return c.NewerTransferFee
+}
+
+// permanentDelegateState is the jsonParsed state of the Token-2022 permanentDelegate mint extension
+type permanentDelegateState struct {
+	Delegate *string `json:"delegate"`
+}
+
+// applyMintExtensions reads the Token-2022 mint accounts and fills the transfer fee and
+// permanent delegate of the matching tokens. Extensions are best effort, balances are
+// still returned when the mints cannot be fetched.
+func (s *tokenService) applyMintExtensions(ctx context.Context, mints []string, byMint map[string]*dto.TokenAccountsResponse) {
+	// The current epoch is only needed for mints with a transfer fee, it is fetched at most once
+	var (
+		epoch        uint64
+		epochFetched bool
+	)
+	currentEpoch := func() uint64 {
+		if !epochFetched {
+			epochFetched = true
+			if info, err := s.client.GetEpochInfo(ctx, rpc.CommitmentConfirmed); err == nil && info != nil {
+				epoch = info.Epoch
+			} else {
+				// Without the epoch a pending fee update is reported as already in effect
+				epoch = math.MaxUint64
+			}
+		}
+		return epoch
+	}
+
+	// getMultipleAccounts accepts at most 100 accounts per call
+	for start := 0; start < len(mints); start += 100 {
+		end := start + 100
+		if end > len(mints) {
+			end = len(mints)
+		}
+		batch := mints[start:end]
+
+		params := []interface{}{batch, map[string]string{"encoding": "jsonParsed"}}
+		var result dto.MintAccountsResult
+		if err := s.client.RPCCallForInto(ctx, &result, "getMultipleAccounts", params); err != nil {
+			return
+		}
+
+		for i, account := range result.Value {
+			if account == nil || i >= len(batch) {
+				continue
+			}
+			token := byMint[batch[i]]
+			for _, extension := range account.Data.Parsed.Info.Extensions {
+				switch extension.Extension {
+				case "transferFeeConfig":
+					var state transferFeeConfigState
+					if err := json.Unmarshal(extension.State, &state); err == nil {
+						fee := state.activeFee(currentEpoch())
+						token.TransferFee = &dto.TransferFee{
+							BasisPoints: fee.TransferFeeBasisPoints,
+							MaximumFee:  fee.MaximumFee,
+							Epoch:       fee.Epoch,
+						}
+					}
+				case "permanentDelegate":
+					var state permanentDelegateState
+					if err := json.Unmarshal(extension.State, &state); err == nil && state.Delegate != nil {
+						token.PermanentDelegate = state.Delegate
+					}
+				}
+			}
+		}
+	}
+}
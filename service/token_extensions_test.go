@@ -0,0 +1,40 @@
+package service
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestTransferFeeConfigActiveFee(t *testing.T) {
+	var config transferFeeConfigState
+	err := json.Unmarshal([]byte(`{
+		"olderTransferFee": {"epoch": 500, "maximumFee": 1000, "transferFeeBasisPoints": 100},
+		"newerTransferFee": {"epoch": 610, "maximumFee": 5000, "transferFeeBasisPoints": 300}
+	}`), &config)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		epoch           uint64
+		wantBasisPoints int
+		wantEpoch       uint64
+	}{
+		{name: "before the update", epoch: 609, wantBasisPoints: 100, wantEpoch: 500},
+		{name: "update epoch", epoch: 610, wantBasisPoints: 300, wantEpoch: 610},
+		{name: "after the update", epoch: 700, wantBasisPoints: 300, wantEpoch: 610},
+		{name: "unknown epoch", epoch: math.MaxUint64, wantBasisPoints: 300, wantEpoch: 610},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee := config.activeFee(tt.epoch)
+			if fee.TransferFeeBasisPoints != tt.wantBasisPoints || fee.Epoch != tt.wantEpoch {
+				t.Errorf("activeFee(%d) = %d bps from epoch %d, want %d bps from epoch %d",
+					tt.epoch, fee.TransferFeeBasisPoints, fee.Epoch, tt.wantBasisPoints, tt.wantEpoch)
+			}
+		})
+	}
+}
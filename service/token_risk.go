@@ -0,0 +1,193 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/errs"
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Risk levels by score: below 25 low, below 50 medium, below 75 high, otherwise critical
+const (
+	RiskLevelLow      = "low"
+	RiskLevelMedium   = "medium"
+	RiskLevelHigh     = "high"
+	RiskLevelCritical = "critical"
+)
+
+// Risk flag severities
+const (
+	riskInfo    = "info"
+	riskWarning = "warning"
+	riskDanger  = "danger"
+)
+
+// riskInputs is everything the risk engine looks at for one token
+type riskInputs struct {
+	token          *entity.Token
+	dailyVolumeUSD float64
+	liquidityUSD   float64
+	poolCount      int
+	analytics      *dto.TokenAnalytics
+}
+
+// assessTokenRisk scores how likely a token is to be a rug pull. Every rule that matches adds its
+// points and the total is capped at 100:
+//
+//	mint_authority       25  supply can still be minted
+//	permanent_delegate   25  a delegate can move or burn any holder's tokens
+//	freeze_authority     20  holder accounts can be frozen
+//	no_liquidity         25  no DEX pool found
+//	low_liquidity        20  pools hold under $10k (10 points under $50k)
+//	holder_concentration 20  largest wallet holds over 50% of supply (10 points over 20%)
+//	top10_concentration  15  ten largest wallets hold over 80% (8 points over 50%)
+//	unknown_holders      10  holder analytics unavailable
+//	new_token            15  minted under a day ago (8 points under a week)
+//	unknown_age           5  mint date unknown
+//	low_volume           10  daily volume under $1k
+//	dev_sold             10  the creator wallet sold
+//	sniper_holdings      10  snipers still hold over 10% of supply
+//	no_metadata_links     5  token list carries no extension metadata (website, coingecko id, ...)
+//
+// Holder rules only apply when on-chain analytics are available, unknown_holders takes their place
+// otherwise so a failed analysis does not make a token look safer.
+func assessTokenRisk(in riskInputs, now time.Time) *dto.TokenRisk {
+	risk := &dto.TokenRisk{Flags: []dto.RiskFlag{}, AssessedAt: now}
+	flag := func(code, severity string, points int, description string) {
+		risk.Flags = append(risk.Flags, dto.RiskFlag{Code: code, Severity: severity, Points: points, Description: description})
+		risk.Score += points
+	}
+
+	token := in.token
+	if token.MintAuthority != nil {
+		flag("mint_authority", riskDanger, 25, "Mint authority is still set, new supply can be minted at any time")
+	}
+	if token.PermanentDelegate != nil {
+		flag("permanent_delegate", riskDanger, 25, "A permanent delegate can transfer or burn tokens from any holder")
+	}
+	if token.FreezeAuthority != nil {
+		flag("freeze_authority", riskDanger, 20, "Freeze authority is still set, holder accounts can be frozen")
+	}
+
+	switch {
+	case in.poolCount == 0:
+		flag("no_liquidity", riskDanger, 25, "No DEX liquidity pool was found")
+	case in.liquidityUSD < 10_000:
+		flag("low_liquidity", riskDanger, 20, fmt.Sprintf("DEX liquidity is only $%.0f", in.liquidityUSD))
+	case in.liquidityUSD < 50_000:
+		flag("low_liquidity", riskWarning, 10, fmt.Sprintf("DEX liquidity is only $%.0f", in.liquidityUSD))
+	}
+
+	if analytics := in.analytics; analytics != nil && analytics.TopHolder > 0 {
+		switch {
+		case analytics.TopHolder > 50:
+			flag("holder_concentration", riskDanger, 20, fmt.Sprintf("The largest wallet holds %.1f%% of supply", analytics.TopHolder))
+		case analytics.TopHolder > 20:
+			flag("holder_concentration", riskWarning, 10, fmt.Sprintf("The largest wallet holds %.1f%% of supply", analytics.TopHolder))
+		}
+		switch {
+		case analytics.TopWallets > 80:
+			flag("top10_concentration", riskDanger, 15, fmt.Sprintf("The ten largest wallets hold %.1f%% of supply", analytics.TopWallets))
+		case analytics.TopWallets > 50:
+			flag("top10_concentration", riskWarning, 8, fmt.Sprintf("The ten largest wallets hold %.1f%% of supply", analytics.TopWallets))
+		}
+		if analytics.DevSold {
+			flag("dev_sold", riskWarning, 10, "The creator wallet has sold tokens")
+		}
+		if analytics.SniperBot > 10 {
+			flag("sniper_holdings", riskWarning, 10, fmt.Sprintf("Sniper wallets still hold %.1f%% of supply", analytics.SniperBot))
+		}
+	} else {
+		flag("unknown_holders", riskWarning, 10, "The holder distribution of the token could not be analyzed")
+	}
+
+	if token.MintedAt == nil {
+		flag("unknown_age", riskInfo, 5, "The mint date of the token is unknown")
+	} else if age := now.Sub(*token.MintedAt); age < 24*time.Hour {
+		flag("new_token", riskDanger, 15, "The token was minted less than a day ago")
+	} else if age < 7*24*time.Hour {
+		flag("new_token", riskWarning, 8, "The token was minted less than a week ago")
+	}
+
+	if in.dailyVolumeUSD < 1_000 {
+		flag("low_volume", riskWarning, 10, fmt.Sprintf("Daily volume is only $%.0f", in.dailyVolumeUSD))
+	}
+
+	if extensions := bytes.TrimSpace(token.Extensions); len(extensions) == 0 || bytes.Equal(extensions, []byte("null")) || bytes.Equal(extensions, []byte("{}")) {
+		flag("no_metadata_links", riskInfo, 5, "The token list has no metadata such as a website or CoinGecko id")
+	}
+
+	if risk.Score > 100 {
+		risk.Score = 100
+	}
+	risk.Level = riskLevel(risk.Score)
+	return risk
+}
+
+// riskLevel maps a risk score onto its level
+func riskLevel(score int) string {
+	switch {
+	case score >= 75:
+		return RiskLevelCritical
+	case score >= 50:
+		return RiskLevelHigh
+	case score >= 25:
+		return RiskLevelMedium
+	default:
+		return RiskLevelLow
+	}
+}
+
+// tokenRisk assesses a token from its lookup data, falling back to the DEX volume when the
+// token list has no daily volume
+func tokenRisk(token *entity.Token, liquidity dto.DexLiquidityInfo, analytics *dto.TokenAnalytics) *dto.TokenRisk {
+	volume := token.DailyVolume
+	if volume == 0 {
+		volume = liquidity.Volume24h
+	}
+	return assessTokenRisk(riskInputs{
+		token:          token,
+		dailyVolumeUSD: volume,
+		liquidityUSD:   liquidity.LiquidityPoolSize,
+		poolCount:      liquidity.PoolCount,
+		analytics:      analytics,
+	}, time.Now())
+}
+
+// GetTokenRisk implements BlockchainService.
+func (s *blockchainService) GetTokenRisk(ctx context.Context, address string) (*dto.TokenRisk, errs.MessageErr) {
+	tokens, err := s.tokenRepo.FindByAddress([]string{address})
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errs.NewNotFound("Token in that contract address not found, please change different contract address")
+	}
+
+	var (
+		pairs     []dto.GetLiquidityRequest
+		errPairs  errs.MessageErr
+		analytics *dto.TokenAnalytics
+		wg        sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		analytics = s.analyzeToken(ctx, address, tokens[0])
+	}()
+	go func() {
+		defer wg.Done()
+		pairs, errPairs = s.marketData.GetDexPairs(ctx, solanaPlatformID, address)
+	}()
+	wg.Wait()
+
+	if errPairs != nil {
+		return nil, errs.NewInternalServerError("Failed to fetch data from API")
+	}
+
+	return tokenRisk(tokens[0], buildDexLiquidityInfo(pairs, 0), analytics), nil
+}
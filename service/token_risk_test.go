@@ -0,0 +1,205 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func stringPtr(v string) *string { return &v }
+
+func TestAssessTokenRisk(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	minted := func(age time.Duration) *time.Time {
+		at := now.Add(-age)
+		return &at
+	}
+	// safeInputs trips none of the rules
+	safeInputs := func() riskInputs {
+		return riskInputs{
+			token: &entity.Token{
+				MintedAt:   minted(30 * 24 * time.Hour),
+				Extensions: []byte(`{"website":"https://example.com"}`),
+			},
+			dailyVolumeUSD: 10_000,
+			liquidityUSD:   100_000,
+			poolCount:      2,
+			analytics:      &dto.TokenAnalytics{TopHolder: 5, TopWallets: 30},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(in *riskInputs)
+		wantCodes []string
+		wantScore int
+		wantLevel string
+	}{
+		{
+			name:      "safe token",
+			modify:    func(in *riskInputs) {},
+			wantCodes: []string{},
+			wantScore: 0,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name: "authorities still set",
+			modify: func(in *riskInputs) {
+				in.token.MintAuthority = stringPtr("authority")
+				in.token.FreezeAuthority = stringPtr("authority")
+			},
+			wantCodes: []string{"mint_authority", "freeze_authority"},
+			wantScore: 45,
+			wantLevel: RiskLevelMedium,
+		},
+		{
+			name:      "no pools",
+			modify:    func(in *riskInputs) { in.poolCount, in.liquidityUSD = 0, 0 },
+			wantCodes: []string{"no_liquidity"},
+			wantScore: 25,
+			wantLevel: RiskLevelMedium,
+		},
+		{
+			name:      "liquidity under $50k",
+			modify:    func(in *riskInputs) { in.liquidityUSD = 20_000 },
+			wantCodes: []string{"low_liquidity"},
+			wantScore: 10,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "liquidity under $10k",
+			modify:    func(in *riskInputs) { in.liquidityUSD = 5_000 },
+			wantCodes: []string{"low_liquidity"},
+			wantScore: 20,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name: "concentrated holders",
+			modify: func(in *riskInputs) {
+				in.analytics = &dto.TokenAnalytics{TopHolder: 60, TopWallets: 90, DevSold: true, SniperBot: 12}
+			},
+			wantCodes: []string{"holder_concentration", "top10_concentration", "dev_sold", "sniper_holdings"},
+			wantScore: 55,
+			wantLevel: RiskLevelHigh,
+		},
+		{
+			name:      "moderately concentrated holders",
+			modify:    func(in *riskInputs) { in.analytics = &dto.TokenAnalytics{TopHolder: 25, TopWallets: 60} },
+			wantCodes: []string{"holder_concentration", "top10_concentration"},
+			wantScore: 18,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "analytics unavailable",
+			modify:    func(in *riskInputs) { in.analytics = nil },
+			wantCodes: []string{"unknown_holders"},
+			wantScore: 10,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "empty analytics",
+			modify:    func(in *riskInputs) { in.analytics = &dto.TokenAnalytics{} },
+			wantCodes: []string{"unknown_holders"},
+			wantScore: 10,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "minted hours ago",
+			modify:    func(in *riskInputs) { in.token.MintedAt = minted(2 * time.Hour) },
+			wantCodes: []string{"new_token"},
+			wantScore: 15,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "minted days ago",
+			modify:    func(in *riskInputs) { in.token.MintedAt = minted(3 * 24 * time.Hour) },
+			wantCodes: []string{"new_token"},
+			wantScore: 8,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "unknown mint date",
+			modify:    func(in *riskInputs) { in.token.MintedAt = nil },
+			wantCodes: []string{"unknown_age"},
+			wantScore: 5,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "low volume",
+			modify:    func(in *riskInputs) { in.dailyVolumeUSD = 500 },
+			wantCodes: []string{"low_volume"},
+			wantScore: 10,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name:      "empty extensions",
+			modify:    func(in *riskInputs) { in.token.Extensions = []byte(" {} ") },
+			wantCodes: []string{"no_metadata_links"},
+			wantScore: 5,
+			wantLevel: RiskLevelLow,
+		},
+		{
+			name: "score capped at 100",
+			modify: func(in *riskInputs) {
+				in.token.MintAuthority = stringPtr("authority")
+				in.token.FreezeAuthority = stringPtr("authority")
+				in.token.PermanentDelegate = stringPtr("delegate")
+				in.token.MintedAt = minted(time.Hour)
+				in.poolCount, in.liquidityUSD = 0, 0
+			},
+			wantCodes: []string{"mint_authority", "permanent_delegate", "freeze_authority", "no_liquidity", "new_token"},
+			wantScore: 100,
+			wantLevel: RiskLevelCritical,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := safeInputs()
+			tt.modify(&in)
+
+			risk := assessTokenRisk(in, now)
+
+			codes := []string{}
+			for _, flag := range risk.Flags {
+				codes = append(codes, flag.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantCodes) {
+				t.Errorf("flags = %v, want %v", codes, tt.wantCodes)
+			}
+			if risk.Score != tt.wantScore {
+				t.Errorf("Score = %d, want %d", risk.Score, tt.wantScore)
+			}
+			if risk.Level != tt.wantLevel {
+				t.Errorf("Level = %q, want %q", risk.Level, tt.wantLevel)
+			}
+			if !risk.AssessedAt.Equal(now) {
+				t.Errorf("AssessedAt = %v, want %v", risk.AssessedAt, now)
+			}
+		})
+	}
+}
+
+func TestRiskLevel(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{0, RiskLevelLow},
+		{24, RiskLevelLow},
+		{25, RiskLevelMedium},
+		{49, RiskLevelMedium},
+		{50, RiskLevelHigh},
+		{74, RiskLevelHigh},
+		{75, RiskLevelCritical},
+		{100, RiskLevelCritical},
+	}
+
+	for _, tt := range tests {
+		if got := riskLevel(tt.score); got != tt.want {
+			t.Errorf("riskLevel(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"blockchain-scrap/dto"
+	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/config"
+	"blockchain-scrap/pkg/errs"
+	"blockchain-scrap/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// tokenUpsertBatchSize is the number of tokens written per upsert statement
+const tokenUpsertBatchSize = 500
+
+// Token list sources that resolve to a Jupiter URL, any other source is a URL or a file path
+const (
+	TokenListStrict = "strict"
+	TokenListAll    = "all"
+)
+
+// TokenSyncService imports a token list into the tokens table
+type TokenSyncService interface {
+	Sync(ctx context.Context, source string) (*entity.TokenSyncRun, errs.MessageErr)
+	RunSchedule(ctx context.Context, source string, interval time.Duration)
+}
+
+// tokenSyncService implements TokenSyncService
+type tokenSyncService struct {
+	tokenRepo repository.TokenRepository
+	syncRepo  repository.TokenSyncRepository
+	client    *http.Client
+}
+
+// NewTokenSyncService creates a TokenSyncService writing to the given repositories
+func NewTokenSyncService(tokenRepo repository.TokenRepository, syncRepo repository.TokenSyncRepository) TokenSyncService {
+	return &tokenSyncService{
+		tokenRepo: tokenRepo,
+		syncRepo:  syncRepo,
+		client:    &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// tokenListURL resolves the "strict" and "all" sources to the Jupiter token list, overridable with
+// TOKEN_LIST_STRICT_URL and TOKEN_LIST_ALL_URL
+func tokenListURL(source string) string {
+	switch source {
+	case TokenListStrict:
+		return config.String("TOKEN_LIST_STRICT_URL", "https://token.jup.ag/strict")
+	case TokenListAll:
+		return config.String("TOKEN_LIST_ALL_URL", "https://token.jup.ag/all")
+	default:
+		return source
+	}
+}
+
+// Sync imports every token of source and records the run. The list is streamed and upserted in
+// batches, so the "all" list never has to fit in memory.
+func (s *tokenSyncService) Sync(ctx context.Context, source string) (*entity.TokenSyncRun, errs.MessageErr) {
+	run := &entity.TokenSyncRun{
+		Source:    source,
+		Status:    entity.TokenSyncStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.syncRepo.Create(ctx, run); err != nil {
+		return nil, err
+	}
+
+	errSync := s.importTokens(ctx, tokenListURL(source), run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = entity.TokenSyncStatusSucceeded
+	if errSync != nil {
+		message := errSync.Message()
+		run.Status = entity.TokenSyncStatusFailed
+		run.Error = &message
+	}
+	// Record the outcome even when ctx was cancelled mid-sync
+	if err := s.syncRepo.Save(context.Background(), run); err != nil {
+		return run, err
+	}
+	if errSync != nil {
+		return run, errSync
+	}
+	return run, nil
+}
+
+// RunSchedule syncs source immediately and then every interval until ctx is cancelled
+func (s *tokenSyncService) RunSchedule(ctx context.Context, source string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := s.Sync(ctx, source)
+		if err != nil {
+			log.Printf("token sync: %s failed: %s", source, err.Message())
+		} else {
+			log.Printf("token sync: %s upserted %d of %d tokens", source, run.TokensUpserted, run.TokensFetched)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// importTokens streams the token list at location and upserts it in batches
+func (s *tokenSyncService) importTokens(ctx context.Context, location string, run *entity.TokenSyncRun) errs.MessageErr {
+	reader, err := s.open(ctx, location)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	if token, errToken := decoder.Token(); errToken != nil || token != json.Delim('[') {
+		return errs.NewUnprocessableEntity("token list must be a JSON array")
+	}
+
+	// A statement cannot upsert the same address twice, later entries of a batch replace earlier ones
+	batch := make([]*entity.Token, 0, tokenUpsertBatchSize)
+	batchIndex := make(map[string]int, tokenUpsertBatchSize)
+	flush := func() errs.MessageErr {
+		if errUpsert := s.tokenRepo.Upsert(ctx, batch); errUpsert != nil {
+			return errUpsert
+		}
+		run.TokensUpserted += len(batch)
+		batch = batch[:0]
+		clear(batchIndex)
+		return nil
+	}
+
+	for decoder.More() {
+		var item dto.JupiterToken
+		if errDecode := decoder.Decode(&item); errDecode != nil {
+			return errs.NewUnprocessableEntity(fmt.Sprintf("invalid token list entry %d: %v", run.TokensFetched, errDecode))
+		}
+		run.TokensFetched++
+		if item.Address == "" {
+			continue
+		}
+
+		if i, ok := batchIndex[item.Address]; ok {
+			batch[i] = toTokenEntity(item)
+			continue
+		}
+		batchIndex[item.Address] = len(batch)
+		batch = append(batch, toTokenEntity(item))
+		if len(batch) == tokenUpsertBatchSize {
+			if errFlush := flush(); errFlush != nil {
+				return errFlush
+			}
+		}
+	}
+	return flush()
+}
+
+// open returns a reader over a token list URL or file
+func (s *tokenSyncService) open(ctx context.Context, location string) (io.ReadCloser, errs.MessageErr) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, errs.NewBadRequest(fmt.Sprintf("failed to open token list: %v", err))
+		}
+		return file, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, errs.NewBadRequest(fmt.Sprintf("invalid token list URL: %v", err))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errs.NewInternalServerError(fmt.Sprintf("failed to fetch token list: %v", err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errs.NewInternalServerError(fmt.Sprintf("failed to fetch token list: status %d", resp.StatusCode))
+	}
+	return resp.Body, nil
+}
+
+// toTokenEntity maps a Jupiter token list entry onto a tokens row
+func toTokenEntity(item dto.JupiterToken) *entity.Token {
+	token := &entity.Token{
+		Address:           item.Address,
+		Name:              item.Name,
+		Symbol:            item.Symbol,
+		Decimals:          item.Decimals,
+		LogoURI:           item.LogoURI,
+		FreezeAuthority:   item.FreezeAuthority,
+		MintAuthority:     item.MintAuthority,
+		PermanentDelegate: item.PermanentDelegate,
+		MintedAt:          item.MintedAt,
+		Tags:              datatypes.JSON(jsonOrNull(item.Tags)),
+		Extensions:        datatypes.JSON(jsonOrNull(item.Extensions)),
+	}
+	if item.DailyVolume != nil {
+		token.DailyVolume = *item.DailyVolume
+	}
+	if item.CreatedAt != nil {
+		token.CreatedAt = *item.CreatedAt
+	}
+	return token
+}
+
+// jsonOrNull keeps a missing JSON field as an explicit null instead of an empty, invalid value
+func jsonOrNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
@@ -3,31 +3,53 @@ package service
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"blockchain-scrap/dto"
 	"blockchain-scrap/entity"
+	"blockchain-scrap/pkg/config"
 	"blockchain-scrap/pkg/errs"
 	"blockchain-scrap/repository"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 )
 
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// WebSocketAuthProtocol is the subprotocol followed by the access token on WebSocket upgrades
+const WebSocketAuthProtocol = "bearer"
+
 // UserService defines the contract for user services
 type UserService interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, errs.MessageErr)
 	Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, errs.MessageErr)
+	Refresh(ctx context.Context, req dto.RefreshRequest) (*dto.LoginResponse, errs.MessageErr)
+	Logout(ctx context.Context, user *entity.User, req dto.LogoutRequest) errs.MessageErr
 	Authentication() gin.HandlerFunc
 }
 
 // userServiceImpl implements UserService
 type userServiceImpl struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
-// NewUserService creates a new instance of UserService
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userServiceImpl{userRepo: repo}
+// NewUserService creates a new instance of UserService.
+// Token lifetimes are read from ACCESS_TOKEN_TTL and REFRESH_TOKEN_TTL (Go durations, e.g. 15m, 720h).
+func NewUserService(repo repository.UserRepository, sessionRepo repository.SessionRepository) UserService {
+	return &userServiceImpl{
+		userRepo:        repo,
+		sessionRepo:     sessionRepo,
+		accessTokenTTL:  config.Duration("ACCESS_TOKEN_TTL", defaultAccessTokenTTL),
+		refreshTokenTTL: config.Duration("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL),
+	}
 }
 
 // Register registers a new user
@@ -57,7 +79,7 @@ func (s *userServiceImpl) Register(ctx context.Context, req dto.RegisterRequest)
 	}, nil
 }
 
-// Login authenticates a user
+// Login authenticates a user and starts a new session
 func (s *userServiceImpl) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, errs.MessageErr) {
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
@@ -68,20 +90,81 @@ func (s *userServiceImpl) Login(ctx context.Context, req dto.LoginRequest) (*dto
 		return nil, errs.NewBadRequest(err.Error())
 	}
 
-	token, err := user.CreateToken()
+	refreshToken, refreshValue, err := s.newRefreshToken(user.ID, uuid.New())
 	if err != nil {
-		return nil, errs.NewInternalServerError(err.Error())
+		return nil, err
+	}
+	if err := s.sessionRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, err
 	}
 
-	return &dto.LoginResponse{
-		Token: token,
-	}, nil
+	return s.issueTokens(user, refreshToken, refreshValue)
+}
+
+// Refresh rotates a refresh token and issues a new access token.
+// Presenting a refresh token that was already rotated revokes its whole family,
+// since it means the token has leaked.
+func (s *userServiceImpl) Refresh(ctx context.Context, req dto.RefreshRequest) (*dto.LoginResponse, errs.MessageErr) {
+	current, err := s.sessionRepo.FindRefreshTokenByHash(ctx, entity.HashRefreshToken(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedByID != nil {
+			if err := s.sessionRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, errs.NewUnauthenticated("Refresh token has been revoked")
+	}
+
+	if !current.IsActive(time.Now()) {
+		return nil, errs.NewUnauthenticated("Refresh token has expired")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, current.UserID)
+	if err != nil {
+		return nil, errs.NewUnauthenticated("User no longer exists")
+	}
+
+	next, nextValue, err := s.newRefreshToken(user.ID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.RotateRefreshToken(ctx, current, next); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, next, nextValue)
+}
+
+// Logout revokes the access token of the current request and, when given, the refresh token family
+func (s *userServiceImpl) Logout(ctx context.Context, user *entity.User, req dto.LogoutRequest) errs.MessageErr {
+	if req.RefreshToken != "" {
+		refreshToken, err := s.sessionRepo.FindRefreshTokenByHash(ctx, entity.HashRefreshToken(req.RefreshToken))
+		if err != nil {
+			return err
+		}
+		if refreshToken.UserID != user.ID {
+			return errs.NewUnauthorized("Refresh token does not belong to this user")
+		}
+		if err := s.sessionRepo.RevokeRefreshTokenFamily(ctx, refreshToken.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	return s.sessionRepo.RevokeAccessToken(ctx, &entity.RevokedAccessToken{
+		JTI:       user.TokenID,
+		UserID:    user.ID,
+		ExpiresAt: user.TokenExpiresAt,
+	})
 }
 
 // Authentication middleware to validate JWT token
 func (s *userServiceImpl) Authentication() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
+		authHeader := authorizationHeader(c)
 
 		var user entity.User
 		if err := user.ValidateToken(authHeader); err != nil {
@@ -89,13 +172,78 @@ func (s *userServiceImpl) Authentication() gin.HandlerFunc {
 			return
 		}
 
+		revoked, err := s.sessionRepo.IsAccessTokenRevoked(c.Request.Context(), user.TokenID)
+		if err != nil {
+			c.AbortWithStatusJSON(err.StatusCode(), err)
+			return
+		}
+		if revoked {
+			errRevoked := errs.NewUnauthenticated("Token has been revoked")
+			c.AbortWithStatusJSON(errRevoked.StatusCode(), errRevoked)
+			return
+		}
+
 		authenticatedUser, err := s.userRepo.FindByEmail(c.Request.Context(), user.Email)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusBadRequest, err)
 			return
 		}
+		authenticatedUser.TokenID = user.TokenID
+		authenticatedUser.TokenExpiresAt = user.TokenExpiresAt
 
 		c.Set("userData", authenticatedUser)
 		c.Next()
 	}
 }
+
+// newRefreshToken builds an unsaved refresh token for a user within the given rotation family
+func (s *userServiceImpl) newRefreshToken(userID, familyID uuid.UUID) (*entity.RefreshToken, string, errs.MessageErr) {
+	value, hash, err := entity.NewRefreshTokenValue()
+	if err != nil {
+		return nil, "", errs.NewInternalServerError("Failed to generate refresh token")
+	}
+
+	return &entity.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}, value, nil
+}
+
+// issueTokens signs a new access token and pairs it with the given refresh token
+func (s *userServiceImpl) issueTokens(user *entity.User, refreshToken *entity.RefreshToken, refreshValue string) (*dto.LoginResponse, errs.MessageErr) {
+	token, expiresAt, err := user.CreateToken(s.accessTokenTTL)
+	if err != nil {
+		return nil, errs.NewInternalServerError(err.Error())
+	}
+
+	return &dto.LoginResponse{
+		Token:            token,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshValue,
+		RefreshExpiresAt: refreshToken.ExpiresAt,
+	}, nil
+}
+
+// authorizationHeader returns the bearer token of a request. Browsers cannot set headers on
+// WebSocket upgrades, so those may pass the access token as the access_token query parameter
+// or as the subprotocols "bearer" and "<token>" instead.
+func authorizationHeader(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" || !websocket.IsWebSocketUpgrade(c.Request) {
+		return header
+	}
+	if token := c.Query("access_token"); token != "" {
+		return "Bearer " + token
+	}
+
+	protocols := websocket.Subprotocols(c.Request)
+	for i, protocol := range protocols {
+		if protocol == WebSocketAuthProtocol && i+1 < len(protocols) {
+			return "Bearer " + protocols[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"blockchain-scrap/repository"
+	"blockchain-scrap/service"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"gorm.io/gorm"
+)
+
+// runSyncTokens implements the sync-tokens subcommand, importing a token list once:
+//
+//	blockchain-scrap sync-tokens [-source strict|all|<url>|<file>]
+func runSyncTokens(db *gorm.DB, args []string) {
+	flags := flag.NewFlagSet("sync-tokens", flag.ExitOnError)
+	source := flags.String("source", service.TokenListStrict, `token list to import: "strict", "all", a URL or a JSON file`)
+	flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	syncService := service.NewTokenSyncService(repository.NewTokenRepository(db), repository.NewTokenSyncRepository(db))
+	run, err := syncService.Sync(ctx, *source)
+	if err != nil {
+		log.Fatalf("token sync failed: %s", err.Message())
+	}
+	log.Printf("token sync %s: upserted %d of %d tokens from %s", run.ID, run.TokensUpserted, run.TokensFetched, run.Source)
+}